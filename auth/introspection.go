@@ -0,0 +1,336 @@
+// auth/introspection.go
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+)
+
+// OutagePolicy decides how tokens are treated when the introspection
+// endpoint cannot be reached
+type OutagePolicy string
+
+const (
+	// OutageFailClosed rejects tokens that cannot be introspected
+	OutageFailClosed OutagePolicy = config.IntrospectionFailClosed
+	// OutageUseCached accepts tokens that were recently active, even if
+	// their cache entry has expired, and rejects others
+	OutageUseCached OutagePolicy = config.IntrospectionUseCached
+	// OutageFailOpen reports tokens as middleware.ErrUnverifiedToken. The
+	// auth middleware rejects them unless AuthConfig.AllowUnverified lets
+	// them through as anonymous requests.
+	OutageFailOpen OutagePolicy = config.IntrospectionFailOpen
+)
+
+// ErrIntrospectionUnavailable is returned when a token cannot be verified
+// because the introspection endpoint is unavailable
+var ErrIntrospectionUnavailable = &middleware.AuthError{
+	Code:    "introspection_unavailable",
+	Message: "Authorization token could not be verified",
+}
+
+// IntrospectionConfig holds configuration for RFC 7662 token introspection
+type IntrospectionConfig struct {
+	IntrospectionURL string
+	ClientID         string
+	ClientSecret     string
+
+	CacheTTL         time.Duration // How long active results are cached (default: 1m)
+	NegativeCacheTTL time.Duration // How long inactive results are cached (default: 10s)
+	StaleTTL         time.Duration // How long past CacheTTL results serve OutageUseCached (default: 15m)
+	MaxCacheEntries  int           // Cache size bound (default: 10000)
+
+	OutagePolicy OutagePolicy // Default: OutageFailClosed
+	HTTPClient   *http.Client // Client for the introspection endpoint (default: 5s timeout)
+}
+
+// IntrospectionConfigFromKeycloak builds introspection configuration for
+// the Keycloak realm's introspection endpoint
+func IntrospectionConfigFromKeycloak(kc config.KeycloakConfig) IntrospectionConfig {
+	timeout := kc.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return IntrospectionConfig{
+		IntrospectionURL: kc.GetIntrospectionURL(),
+		ClientID:         kc.ClientID,
+		ClientSecret:     kc.ClientSecret,
+		CacheTTL:         kc.IntrospectionCacheTTL,
+		NegativeCacheTTL: kc.IntrospectionNegativeCacheTTL,
+		OutagePolicy:     OutagePolicy(kc.IntrospectionOutagePolicy),
+		HTTPClient:       &http.Client{Timeout: timeout},
+	}
+}
+
+// introspectionResult is a cached introspection outcome
+type introspectionResult struct {
+	active    bool
+	claims    map[string]interface{}
+	tokenExp  time.Time // Zero if the token has no exp
+	expiresAt time.Time // End of the cache entry's freshness
+}
+
+// IntrospectionValidator validates opaque tokens against an RFC 7662
+// introspection endpoint. Use its Validate method as
+// middleware.AuthConfig.TokenValidator.
+type IntrospectionValidator struct {
+	config IntrospectionConfig
+
+	mu    sync.Mutex
+	cache map[string]*introspectionResult
+}
+
+// NewIntrospectionValidator creates an introspection validator, using
+// defaults for zero values
+func NewIntrospectionValidator(config IntrospectionConfig) (*IntrospectionValidator, error) {
+	if config.IntrospectionURL == "" {
+		return nil, errors.New("introspection URL is required")
+	}
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, errors.New("client ID and client secret are required")
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = time.Minute
+	}
+	if config.NegativeCacheTTL <= 0 {
+		config.NegativeCacheTTL = 10 * time.Second
+	}
+	if config.StaleTTL <= 0 {
+		config.StaleTTL = 15 * time.Minute
+	}
+	if config.MaxCacheEntries <= 0 {
+		config.MaxCacheEntries = 10000
+	}
+	switch config.OutagePolicy {
+	case OutageFailClosed, OutageFailOpen, OutageUseCached:
+	case "":
+		config.OutagePolicy = OutageFailClosed
+	default:
+		return nil, fmt.Errorf("unknown outage policy %q", config.OutagePolicy)
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return &IntrospectionValidator{
+		config: config,
+		cache:  make(map[string]*introspectionResult),
+	}, nil
+}
+
+// NewKeycloakIntrospectionValidator creates a validator for the realm's
+// introspection endpoint, authenticating as the service's Keycloak client
+func NewKeycloakIntrospectionValidator(kc config.KeycloakConfig) (*IntrospectionValidator, error) {
+	return NewIntrospectionValidator(IntrospectionConfigFromKeycloak(kc))
+}
+
+// Validate implements middleware.AuthConfig.TokenValidator
+func (v *IntrospectionValidator) Validate(token string) (map[string]interface{}, error) {
+	return v.ValidateContext(context.Background(), token)
+}
+
+// ValidateContext introspects the token, returning its claims if active
+func (v *IntrospectionValidator) ValidateContext(ctx context.Context, token string) (map[string]interface{}, error) {
+	if token == "" {
+		return nil, middleware.ErrMissingToken
+	}
+
+	key := hashToken(token)
+	now := time.Now()
+
+	cached := v.lookup(key)
+	if cached != nil && now.Before(cached.expiresAt) {
+		return cached.verdict(now)
+	}
+
+	result, err := v.introspect(ctx, token)
+	if err != nil {
+		logging.FromContext(ctx).Warn("token introspection failed",
+			slog.String("policy", string(v.config.OutagePolicy)),
+			logging.Err(err),
+		)
+		return v.onOutage(cached, now)
+	}
+
+	v.store(key, result, now)
+	return result.verdict(now)
+}
+
+// Purge removes all cached results
+func (v *IntrospectionValidator) Purge() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cache = make(map[string]*introspectionResult)
+}
+
+// onOutage applies the outage policy
+func (v *IntrospectionValidator) onOutage(cached *introspectionResult, now time.Time) (map[string]interface{}, error) {
+	switch v.config.OutagePolicy {
+	case OutageFailOpen:
+		return nil, middleware.ErrUnverifiedToken
+	case OutageUseCached:
+		if cached != nil && cached.active && now.Before(cached.expiresAt.Add(v.config.StaleTTL)) {
+			return cached.verdict(now)
+		}
+	}
+	return nil, ErrIntrospectionUnavailable
+}
+
+// verdict returns the claims of an active, unexpired token
+func (r *introspectionResult) verdict(now time.Time) (map[string]interface{}, error) {
+	if !r.active {
+		return nil, middleware.ErrInvalidToken
+	}
+	if !r.tokenExp.IsZero() && !now.Before(r.tokenExp) {
+		return nil, middleware.ErrExpiredToken
+	}
+
+	// Copy so handlers cannot modify the cached claims
+	claims := make(map[string]interface{}, len(r.claims))
+	for key, value := range r.claims {
+		claims[key] = value
+	}
+	return claims, nil
+}
+
+// lookup returns the cached result for key, if any
+func (v *IntrospectionValidator) lookup(key string) *introspectionResult {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.cache[key]
+}
+
+// store caches a result, never beyond the token's own expiry
+func (v *IntrospectionValidator) store(key string, result *introspectionResult, now time.Time) {
+	ttl := v.config.NegativeCacheTTL
+	if result.active {
+		ttl = v.config.CacheTTL
+	}
+	result.expiresAt = now.Add(ttl)
+	if !result.tokenExp.IsZero() && result.tokenExp.Before(result.expiresAt) {
+		result.expiresAt = result.tokenExp
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, exists := v.cache[key]; !exists && len(v.cache) >= v.config.MaxCacheEntries {
+		v.evictLocked(now)
+	}
+	v.cache[key] = result
+}
+
+// evictLocked drops entries no longer useful, or an arbitrary one if all
+// are. Callers hold mu.
+func (v *IntrospectionValidator) evictLocked(now time.Time) {
+	for key, entry := range v.cache {
+		if now.After(entry.expiresAt.Add(v.config.StaleTTL)) {
+			delete(v.cache, key)
+		}
+	}
+	if len(v.cache) < v.config.MaxCacheEntries {
+		return
+	}
+	for key := range v.cache {
+		delete(v.cache, key)
+		return
+	}
+}
+
+// introspect calls the introspection endpoint. Errors indicate the
+// endpoint could not give an answer, not that the token is invalid.
+func (v *IntrospectionValidator) introspect(ctx context.Context, token string) (*introspectionResult, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.config.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(v.config.ClientID), url.QueryEscape(v.config.ClientSecret))
+
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned %d", resp.StatusCode)
+	}
+
+	var payload map[string]interface{}
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, 1<<20))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	return parseIntrospection(payload), nil
+}
+
+// parseIntrospection maps an introspection response to context claims.
+// The raw response is kept, with sub also available as user_id, scope
+// split into scopes, exp as a time.Time and Keycloak realm roles as roles.
+func parseIntrospection(payload map[string]interface{}) *introspectionResult {
+	active, _ := payload["active"].(bool)
+	result := &introspectionResult{active: active}
+	if !active {
+		return result
+	}
+
+	claims := make(map[string]interface{}, len(payload)+4)
+	for key, value := range payload {
+		claims[key] = value
+	}
+
+	if sub, ok := payload["sub"].(string); ok && sub != "" {
+		claims["user_id"] = sub
+	}
+	if scope, ok := payload["scope"].(string); ok {
+		claims["scopes"] = strings.Fields(scope)
+	}
+	if exp, ok := payload["exp"].(json.Number); ok {
+		if seconds, err := exp.Int64(); err == nil {
+			result.tokenExp = time.Unix(seconds, 0)
+			claims["exp"] = result.tokenExp
+		}
+	}
+	if realmAccess, ok := payload["realm_access"].(map[string]interface{}); ok {
+		if list, ok := realmAccess["roles"].([]interface{}); ok {
+			roles := make([]string, 0, len(list))
+			for _, role := range list {
+				if name, ok := role.(string); ok {
+					roles = append(roles, name)
+				}
+			}
+			claims["roles"] = roles
+		}
+	}
+
+	result.claims = claims
+	return result
+}
+
+// hashToken keys the cache so raw tokens are not kept in memory
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+// auth/revocation.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore records revoked tokens and implements
+// middleware.RevocationChecker
+type RevocationStore interface {
+	// RevokeToken revokes a single token by ID until it expires
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// RevokeSubject revokes every token of subject issued before the cutoff
+	RevokeSubject(ctx context.Context, subject string, before time.Time) error
+	IsRevoked(ctx context.Context, tokenID, subject string, issuedAt time.Time) (bool, error)
+}
+
+// defaultRevocationTTL bounds entries for tokens revoked without an expiry
+const defaultRevocationTTL = 24 * time.Hour
+
+// MemoryRevocationStore is an in-process RevocationStore. On its own it only
+// covers a single replica; feed it with database.ListenForRevocations to
+// share revocations between replicas.
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	tokens    map[string]time.Time // Token ID to expiry
+	subjects  map[string]time.Time // Subject to revocation cutoff
+	lastSweep time.Time
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:    make(map[string]time.Time),
+		subjects:  make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// RevokeToken implements RevocationStore
+func (s *MemoryRevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return nil
+	}
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(defaultRevocationTTL)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exists := s.tokens[tokenID]; !exists || expiresAt.After(current) {
+		s.tokens[tokenID] = expiresAt
+	}
+	s.sweepLocked(time.Now())
+	return nil
+}
+
+// RevokeSubject implements RevocationStore. Token iat claims have second
+// precision, so the cutoff is truncated to the second: tokens issued in the
+// same second as the revocation remain valid.
+func (s *MemoryRevocationStore) RevokeSubject(ctx context.Context, subject string, before time.Time) error {
+	if subject == "" {
+		return nil
+	}
+	before = before.Truncate(time.Second)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exists := s.subjects[subject]; !exists || before.After(current) {
+		s.subjects[subject] = before
+	}
+	return nil
+}
+
+// IsRevoked implements middleware.RevocationChecker. Tokens without an iat
+// claim are treated as revoked once their subject has a cutoff.
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, tokenID, subject string, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if tokenID != "" {
+		if expiresAt, exists := s.tokens[tokenID]; exists && time.Now().Before(expiresAt) {
+			return true, nil
+		}
+	}
+
+	if subject != "" {
+		if before, exists := s.subjects[subject]; exists && (issuedAt.IsZero() || issuedAt.Before(before)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// sweepLocked drops expired token entries at most once a minute. Callers
+// hold mu.
+func (s *MemoryRevocationStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for tokenID, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, tokenID)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// auth/token_source.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+// Token is an access token issued to this service
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Expiry      time.Time // Zero if the token does not expire
+}
+
+// Valid reports whether the token is set and not expired at now
+func (t *Token) Valid(now time.Time) bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || now.Before(t.Expiry))
+}
+
+// TokenSource supplies access tokens for outbound calls
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenError is returned when the token endpoint rejects a request
+type TokenError struct {
+	StatusCode  int
+	Code        string // OAuth2 error code, e.g. invalid_client
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("token endpoint returned %d: %s: %s", e.StatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("token endpoint returned %d: %s", e.StatusCode, e.Code)
+}
+
+// ClientCredentialsConfig holds configuration for the OAuth2 client
+// credentials grant
+type ClientCredentialsConfig struct {
+	TokenURL      string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+	RefreshBefore time.Duration // Refresh this long before expiry (default: 30s)
+	HTTPClient    *http.Client  // Client for the token endpoint (default: 10s timeout)
+}
+
+// ClientCredentialsConfigFromKeycloak builds client credentials
+// configuration for the Keycloak realm's token endpoint
+func ClientCredentialsConfigFromKeycloak(kc config.KeycloakConfig) ClientCredentialsConfig {
+	timeout := kc.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return ClientCredentialsConfig{
+		TokenURL:      kc.GetTokenURL(),
+		ClientID:      kc.ClientID,
+		ClientSecret:  kc.ClientSecret,
+		RefreshBefore: 30 * time.Second,
+		HTTPClient:    &http.Client{Timeout: timeout},
+	}
+}
+
+// ClientCredentialsSource obtains tokens with the client credentials grant.
+// Tokens are cached and refreshed in the background shortly before they
+// expire; concurrent callers share a single request to the token endpoint.
+type ClientCredentialsSource struct {
+	config ClientCredentialsConfig
+
+	mu      sync.Mutex
+	token   *Token
+	pending *tokenFetch
+}
+
+// tokenFetch is an in-flight token request shared by concurrent callers
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewClientCredentialsSource creates a client credentials token source
+func NewClientCredentialsSource(config ClientCredentialsConfig) (*ClientCredentialsSource, error) {
+	if config.TokenURL == "" {
+		return nil, errors.New("token URL is required")
+	}
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, errors.New("client ID and client secret are required")
+	}
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = 30 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &ClientCredentialsSource{config: config}, nil
+}
+
+// NewKeycloakTokenSource creates a token source for the service's
+// Keycloak client
+func NewKeycloakTokenSource(kc config.KeycloakConfig) (*ClientCredentialsSource, error) {
+	return NewClientCredentialsSource(ClientCredentialsConfigFromKeycloak(kc))
+}
+
+// Token returns a cached token, fetching a new one when none is valid
+func (s *ClientCredentialsSource) Token(ctx context.Context) (*Token, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	token := s.token
+	if token.Valid(now) {
+		// Refresh early in the background while the current token is still usable
+		if !token.Expiry.IsZero() && now.After(token.Expiry.Add(-s.config.RefreshBefore)) {
+			s.startFetchLocked()
+		}
+		s.mu.Unlock()
+		return token, nil
+	}
+	fetch := s.startFetchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token so the next call fetches a new one
+func (s *ClientCredentialsSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+}
+
+// startFetchLocked returns the in-flight fetch, starting one if needed.
+// Callers hold mu.
+func (s *ClientCredentialsSource) startFetchLocked() *tokenFetch {
+	if s.pending != nil {
+		return s.pending
+	}
+
+	fetch := &tokenFetch{done: make(chan struct{})}
+	s.pending = fetch
+
+	// The fetch outlives any single caller, so it uses its own context
+	// bounded by the HTTP client's timeout
+	go func() {
+		fetch.token, fetch.err = s.fetch(context.Background())
+
+		s.mu.Lock()
+		if fetch.err == nil {
+			s.token = fetch.token
+		} else {
+			logging.Default().Warn("failed to obtain client credentials token",
+				slog.String("client_id", s.config.ClientID),
+				logging.Err(fetch.err),
+			)
+		}
+		s.pending = nil
+		s.mu.Unlock()
+
+		close(fetch.done)
+	}()
+
+	return fetch
+}
+
+// fetch requests a token from the token endpoint
+func (s *ClientCredentialsSource) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+
+	requestedAt := time.Now()
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var payload struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.Unmarshal(body, &payload)
+
+	if resp.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{StatusCode: resp.StatusCode, Code: payload.Error, Description: payload.ErrorDescription}
+		if tokenErr.Code == "" {
+			tokenErr.Code = http.StatusText(resp.StatusCode)
+		}
+		return nil, tokenErr
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", decodeErr)
+	}
+	if payload.AccessToken == "" {
+		return nil, errors.New("token response did not include an access token")
+	}
+
+	token := &Token{AccessToken: payload.AccessToken, TokenType: payload.TokenType}
+	if token.TokenType == "" {
+		token.TokenType = "Bearer"
+	}
+	if payload.ExpiresIn > 0 {
+		// Measure from the request so network latency shortens, not extends, the lifetime
+		token.Expiry = requestedAt.Add(time.Duration(payload.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
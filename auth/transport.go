@@ -0,0 +1,79 @@
+// auth/transport.go
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TransportConfig holds configuration for an authorizing round tripper
+type TransportConfig struct {
+	Source TokenSource       // Source of the service's access tokens
+	Next   http.RoundTripper // Wrapped transport (default: http.DefaultTransport)
+}
+
+// NewTransport returns a round tripper that authorizes every request with
+// a token from the source, replacing any Authorization header. Use it as
+// client.Config.Transport, with PropagateToken disabled, for calls made as
+// the service rather than on behalf of the caller.
+//
+// If a request is rejected with 401 and the source can be invalidated, the
+// token is dropped and the request is retried once with a fresh token.
+func NewTransport(config TransportConfig) http.RoundTripper {
+	if config.Next == nil {
+		config.Next = http.DefaultTransport
+	}
+	return &transport{config: config}
+}
+
+// invalidator is implemented by token sources that cache tokens
+type invalidator interface {
+	Invalidate()
+}
+
+type transport struct {
+	config TransportConfig
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req, req.Body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	source, ok := t.config.Source.(invalidator)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	body := req.Body
+	if req.GetBody != nil {
+		if body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	source.Invalidate()
+	return t.send(req, body)
+}
+
+// send authorizes a copy of req and sends it
+func (t *transport) send(req *http.Request, body io.ReadCloser) (*http.Response, error) {
+	token, err := t.config.Source.Token(req.Context())
+	if err != nil {
+		if body != nil {
+			body.Close()
+		}
+		return nil, fmt.Errorf("failed to obtain access token: %w", err)
+	}
+
+	authorized := req.Clone(req.Context())
+	authorized.Body = body
+	authorized.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return t.config.Next.RoundTrip(authorized)
+}
@@ -0,0 +1,339 @@
+// client/client.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+)
+
+// Config holds configuration for the outbound HTTP client
+type Config struct {
+	BaseURL        string
+	Timeout        time.Duration // Per-call timeout covering all attempts (0 = none beyond the context)
+	AttemptTimeout time.Duration // Per-attempt timeout (0 = none)
+
+	// Retry policy. Only idempotent requests (GET, HEAD, OPTIONS, PUT,
+	// DELETE, or any request carrying an Idempotency-Key header) are retried.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	RetryStatuses  []int // Statuses that trigger a retry (default: 429, 502, 503, 504)
+
+	// Forward the caller's bearer token from the request context. It is only
+	// sent to BaseURL's scheme and host, and to TokenHosts.
+	PropagateToken bool
+	TokenHosts     []string // Other hosts (host[:port]) trusted with the caller's token
+	UserAgent      string
+	Headers        map[string]string // Sent with every request
+	Transport      http.RoundTripper // Optional custom transport
+	Tracer         *tracing.Tracer   // Tracer for client spans (default: tracing.Default())
+}
+
+// DefaultConfig returns default client configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:        30 * time.Second,
+		AttemptTimeout: 10 * time.Second,
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		PropagateToken: true,
+	}
+}
+
+// Client is an HTTP client for calling other services. It propagates the
+// request ID, trace context and caller's token, and retries transient
+// failures.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+	tokenBase  *url.URL // Origin of BaseURL, nil if unset or invalid
+}
+
+// New creates a client with the given configuration
+func New(config Config) *Client {
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	client := &Client{
+		config:     config,
+		httpClient: &http.Client{Transport: transport},
+	}
+	if base, err := url.Parse(config.BaseURL); err == nil && base.Host != "" {
+		client.tokenBase = base
+	}
+	return client
+}
+
+// NewDefault creates a client for baseURL with default configuration
+func NewDefault(baseURL string) *Client {
+	config := DefaultConfig()
+	config.BaseURL = baseURL
+	return New(config)
+}
+
+// Do sends the request, retrying transient failures. The returned response
+// may have any status; use DecodeError to turn error statuses into
+// *responses.APIError.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	cancel := func() {}
+	if c.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+	}
+
+	resp, err := c.doWithRetries(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Keep the call's context alive until the body has been read
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Get sends a GET request and decodes the JSON response into out
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post sends body as JSON and decodes the JSON response into out
+func (c *Client) Post(ctx context.Context, path string, body, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodPost, path, body, out)
+}
+
+// Put sends body as JSON and decodes the JSON response into out
+func (c *Client) Put(ctx context.Context, path string, body, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodPut, path, body, out)
+}
+
+// Patch sends body as JSON and decodes the JSON response into out
+func (c *Client) Patch(ctx context.Context, path string, body, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodPatch, path, body, out)
+}
+
+// Delete sends a DELETE request and decodes the JSON response into out
+func (c *Client) Delete(ctx context.Context, path string, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodDelete, path, nil, out)
+}
+
+// DoJSON sends a request with an optional JSON body and decodes a JSON
+// response into out (which may be nil). Error statuses are returned as
+// *responses.APIError.
+func (c *Client) DoJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := DecodeError(resp); err != nil {
+		return err
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// url joins the base URL and path
+func (c *Client) url(path string) string {
+	if c.config.BaseURL == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(c.config.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// doWithRetries sends attempts until one succeeds, the error is not
+// retryable, or retries are exhausted
+func (c *Client) doWithRetries(ctx context.Context, req *http.Request) (*http.Response, error) {
+	retryable := c.config.MaxRetries > 0 && isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req, attempt)
+
+		if !retryable || attempt >= c.config.MaxRetries || !c.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = retryAfter
+			}
+		}
+
+		// Give up early if the wait would outlast the call deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		logging.FromContext(ctx).Debug("retrying outbound request",
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.Redacted()),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+		)
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends a single attempt with propagation headers and a client span
+func (c *Client) attempt(ctx context.Context, original *http.Request, attempt int) (*http.Response, error) {
+	tracer := c.config.Tracer
+	if tracer == nil {
+		tracer = tracing.Default()
+	}
+
+	ctx, span := tracer.Start(ctx, "HTTP "+original.Method, tracing.SpanKindClient)
+	defer span.End()
+
+	attemptCtx := ctx
+	cancel := func() {}
+	if c.config.AttemptTimeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, c.config.AttemptTimeout)
+	}
+
+	req := original.Clone(attemptCtx)
+	if attempt > 0 && original.GetBody != nil {
+		body, err := original.GetBody()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		req.Body = body
+	}
+
+	c.applyHeaders(ctx, req)
+	tracing.InjectContext(ctx, req.Header)
+
+	span.SetAttribute("http.request.method", req.Method)
+	span.SetAttribute("url.full", req.URL.Redacted())
+	span.SetAttribute("server.address", req.URL.Hostname())
+	if attempt > 0 {
+		span.SetAttribute("http.request.resend_count", attempt)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(tracing.StatusError, http.StatusText(resp.StatusCode))
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// applyHeaders sets configured headers and propagates the request ID and
+// caller's token from ctx
+func (c *Client) applyHeaders(ctx context.Context, req *http.Request) {
+	for key, value := range c.config.Headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	if c.config.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	if requestID := logging.RequestIDFromContext(ctx); requestID != "" && req.Header.Get(middleware.RequestIDHeader) == "" {
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+	}
+
+	if c.config.PropagateToken && c.trustedWithToken(req.URL) && req.Header.Get("Authorization") == "" {
+		if token, ok := middleware.TokenFromContext(ctx); ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+// trustedWithToken reports whether the caller's token may be sent to u, so
+// absolute URLs to third parties never receive it
+func (c *Client) trustedWithToken(u *url.URL) bool {
+	if c.tokenBase != nil && strings.EqualFold(u.Scheme, c.tokenBase.Scheme) && strings.EqualFold(u.Host, c.tokenBase.Host) {
+		return true
+	}
+	for _, host := range c.config.TokenHosts {
+		if strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelOnClose releases a context when the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,82 @@
+// client/errors.go
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is read
+const maxErrorBodyBytes = 64 << 10
+
+// DecodeError returns nil for 2xx and 3xx responses. For error statuses it
+// reads the body and returns a *responses.APIError, decoded from the
+// responses.ErrorResponse envelope when the service used one.
+func DecodeError(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+
+	apiErr := &responses.APIError{
+		StatusCode: resp.StatusCode,
+		Code:       codeForStatus(resp.StatusCode),
+		Message:    http.StatusText(resp.StatusCode),
+	}
+
+	var envelope responses.ErrorResponse
+	if err := json.Unmarshal(body, &envelope); err == nil && (envelope.Code != "" || envelope.Error != "") {
+		if envelope.Code != "" {
+			apiErr.Code = envelope.Code
+		}
+		if envelope.Error != "" {
+			apiErr.Message = envelope.Error
+		}
+		apiErr.Details = envelope.Details
+		apiErr.Metadata = envelope.Metadata
+	} else if len(body) > 0 {
+		apiErr.Details = string(body)
+	}
+
+	return apiErr
+}
+
+// AsAPIError returns the *responses.APIError in err's chain, if any
+func AsAPIError(err error) (*responses.APIError, bool) {
+	var apiErr *responses.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// codeForStatus maps a status without an error envelope to an error code
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return responses.ErrCodeBadRequest
+	case http.StatusUnauthorized:
+		return responses.ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return responses.ErrCodeForbidden
+	case http.StatusNotFound:
+		return responses.ErrCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return responses.ErrCodeMethodNotAllowed
+	case http.StatusConflict:
+		return responses.ErrCodeConflict
+	case http.StatusUnprocessableEntity:
+		return responses.ErrCodeUnprocessableEntity
+	case http.StatusTooManyRequests:
+		return responses.ErrCodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return responses.ErrCodeServiceUnavailable
+	default:
+		return responses.ErrCodeExternalServiceError
+	}
+}
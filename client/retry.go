@@ -0,0 +1,106 @@
+// client/retry.go
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdempotencyKeyHeader marks a non-idempotent request as safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// isIdempotent reports whether the request can be safely retried
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// shouldRetry reports whether an attempt failed transiently
+func (c *Client) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	// The call itself was cancelled or timed out
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// Per-attempt timeouts are retried; other context errors are not
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		// Errors such as a rejection by an open circuit breaker opt out
+		var retryable interface{ Retryable() bool }
+		if errors.As(err, &retryable) {
+			return retryable.Retryable()
+		}
+		return true
+	}
+
+	return slices.Contains(c.retryStatuses(), resp.StatusCode)
+}
+
+// retryStatuses returns the configured retry statuses or the defaults
+func (c *Client) retryStatuses() []int {
+	if len(c.config.RetryStatuses) > 0 {
+		return c.config.RetryStatuses
+	}
+	return DefaultConfig().RetryStatuses
+}
+
+// backoff returns the delay before the retry following attempt, using
+// exponential backoff with jitter between half and the full delay
+func (c *Client) backoff(attempt int) time.Duration {
+	initial := c.config.InitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	maxBackoff := c.config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Second
+	}
+
+	delay := initial
+	for i := 0; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
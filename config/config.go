@@ -13,9 +13,14 @@ import (
 type Config struct {
 	Port           string
 	AllowedOrigins []string
+	CORSConfig     CORSConfig
+	ServerConfig   ServerConfig
 	DatabaseConfig DatabaseConfig
 	KeycloakConfig KeycloakConfig
+	TracingConfig  TracingConfig
 	LogLevel       string
+	LogFormat      string
+	LogBodies      string
 	Environment    string
 	ServiceName    string
 	ServiceVersion string
@@ -26,9 +31,14 @@ func LoadFromEnv() *Config {
 	return &Config{
 		Port:           utils.GetEnv("PORT", "8000"),
 		AllowedOrigins: parseOrigins(utils.GetEnv("ALLOWED_ORIGINS", "http://localhost:3000")),
+		CORSConfig:     LoadCORSConfig(),
+		ServerConfig:   LoadServerConfig(),
 		DatabaseConfig: LoadDatabaseConfig(),
 		KeycloakConfig: LoadKeycloakConfig(),
+		TracingConfig:  LoadTracingConfig(),
 		LogLevel:       utils.GetEnv("LOG_LEVEL", "info"),
+		LogFormat:      utils.GetEnv("LOG_FORMAT", ""),
+		LogBodies:      utils.GetEnv("LOG_BODIES", ""),
 		Environment:    utils.GetEnv("ENVIRONMENT", "dev"),
 		ServiceName:    utils.GetEnv("SERVICE_NAME", "microservice"),
 		ServiceVersion: utils.GetEnv("SERVICE_VERSION", "1.0.0"),
@@ -60,6 +70,18 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("SERVICE_NAME is required")
 	}
 
+	if err := c.CORSConfig.Validate(c.AllowedOrigins); err != nil {
+		return fmt.Errorf("cors config: %w", err)
+	}
+
+	if err := c.ServerConfig.Validate(); err != nil {
+		return fmt.Errorf("server config: %w", err)
+	}
+
+	if c.ServerConfig.AdminPort != "" && c.ServerConfig.AdminPort == c.Port {
+		return fmt.Errorf("ADMIN_PORT must differ from PORT")
+	}
+
 	if err := c.DatabaseConfig.Validate(); err != nil {
 		return fmt.Errorf("database config: %w", err)
 	}
@@ -68,6 +90,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("keycloak config: %w", err)
 	}
 
+	if err := c.TracingConfig.Validate(); err != nil {
+		return fmt.Errorf("tracing config: %w", err)
+	}
+
 	return nil
 }
 
@@ -102,6 +128,66 @@ func (c *Config) GetLogLevel() LogLevel {
 	}
 }
 
+// Redacted returns a copy of the configuration with secrets masked, safe
+// to expose through operational endpoints
+func (c *Config) Redacted() Config {
+	redacted := *c
+	redacted.AllowedOrigins = append([]string(nil), c.AllowedOrigins...)
+	redacted.CORSConfig.AllowedMethods = append([]string(nil), c.CORSConfig.AllowedMethods...)
+	redacted.CORSConfig.AllowedHeaders = append([]string(nil), c.CORSConfig.AllowedHeaders...)
+	redacted.CORSConfig.ExposedHeaders = append([]string(nil), c.CORSConfig.ExposedHeaders...)
+	redacted.ServerConfig.TrustedProxies = append([]string(nil), c.ServerConfig.TrustedProxies...)
+
+	if redacted.DatabaseConfig.Password != "" {
+		redacted.DatabaseConfig.Password = RedactedValue
+	}
+
+	if redacted.KeycloakConfig.ClientSecret != "" {
+		redacted.KeycloakConfig.ClientSecret = RedactedValue
+	}
+
+	if len(c.TracingConfig.OTLPHeaders) > 0 {
+		redacted.TracingConfig.OTLPHeaders = make(map[string]string, len(c.TracingConfig.OTLPHeaders))
+		for key := range c.TracingConfig.OTLPHeaders {
+			redacted.TracingConfig.OTLPHeaders[key] = RedactedValue
+		}
+	}
+
+	return redacted
+}
+
+// RedactedValue replaces secrets in redacted configuration
+const RedactedValue = "[REDACTED]"
+
+// GetLogFormat returns the log output format. JSON is used by default
+// outside development so log pipelines can parse it.
+func (c *Config) GetLogFormat() string {
+	switch strings.ToLower(c.LogFormat) {
+	case "json":
+		return "json"
+	case "text":
+		return "text"
+	}
+
+	if c.IsDevelopment() {
+		return "text"
+	}
+	return "json"
+}
+
+// ShouldLogBodies reports whether request and response bodies are captured
+// in access logs. Capture is off by default in production.
+func (c *Config) ShouldLogBodies() bool {
+	switch strings.ToLower(c.LogBodies) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return !c.IsProduction()
+}
+
 // parseOrigins parses the origins string into a slice
 func parseOrigins(origins string) []string {
 	if origins == "" {
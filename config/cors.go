@@ -0,0 +1,126 @@
+// config/cors.go
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+)
+
+// CORSConfig holds CORS settings. Allowed origins come from ALLOWED_ORIGINS.
+type CORSConfig struct {
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         time.Duration
+
+	// Credentials are allowed unless disabled, so hand-built
+	// configurations keep them
+	DisableCredentials bool
+}
+
+// Default CORS settings, as comma-separated lists
+const (
+	DefaultCORSAllowedMethods = "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"
+	DefaultCORSAllowedHeaders = "Origin,Content-Length,Content-Type,Authorization,X-Requested-With,Accept,Accept-Encoding,Accept-Language,Cache-Control,X-Request-ID,Idempotency-Key,X-CSRF-Token"
+	DefaultCORSExposedHeaders = "Content-Length,Content-Type,X-Request-ID,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset"
+	DefaultCORSMaxAge         = 12 * time.Hour
+)
+
+// DefaultCORSConfig returns the default CORS settings
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: parseStringSlice(DefaultCORSAllowedMethods),
+		AllowedHeaders: parseStringSlice(DefaultCORSAllowedHeaders),
+		ExposedHeaders: parseStringSlice(DefaultCORSExposedHeaders),
+		MaxAge:         DefaultCORSMaxAge,
+	}
+}
+
+// LoadCORSConfig loads CORS configuration from environment
+func LoadCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods:     parseStringSlice(utils.GetEnv("CORS_ALLOWED_METHODS", DefaultCORSAllowedMethods)),
+		AllowedHeaders:     parseStringSlice(utils.GetEnv("CORS_ALLOWED_HEADERS", DefaultCORSAllowedHeaders)),
+		ExposedHeaders:     parseStringSlice(utils.GetEnv("CORS_EXPOSED_HEADERS", DefaultCORSExposedHeaders)),
+		MaxAge:             utils.GetEnvDuration("CORS_MAX_AGE", DefaultCORSMaxAge),
+		DisableCredentials: !utils.GetEnvBool("CORS_ALLOW_CREDENTIALS", true),
+	}
+}
+
+// WithDefaults returns the configuration with empty values replaced by
+// the defaults
+func (cc CORSConfig) WithDefaults() CORSConfig {
+	defaults := DefaultCORSConfig()
+	if len(cc.AllowedMethods) == 0 {
+		cc.AllowedMethods = defaults.AllowedMethods
+	}
+	if len(cc.AllowedHeaders) == 0 {
+		cc.AllowedHeaders = defaults.AllowedHeaders
+	}
+	if len(cc.ExposedHeaders) == 0 {
+		cc.ExposedHeaders = defaults.ExposedHeaders
+	}
+	if cc.MaxAge == 0 {
+		cc.MaxAge = defaults.MaxAge
+	}
+	return cc
+}
+
+// AllowsCredentials returns true unless credentials are disabled
+func (cc CORSConfig) AllowsCredentials() bool {
+	return !cc.DisableCredentials
+}
+
+// Validate validates the CORS configuration against the allowed origins
+func (cc *CORSConfig) Validate(origins []string) error {
+	if cc.MaxAge < 0 {
+		return fmt.Errorf("CORS max age cannot be negative")
+	}
+
+	return ValidateOrigins(origins, cc.AllowsCredentials())
+}
+
+// ValidateOrigins checks that each origin is "*", scheme://host[:port] or a
+// wildcard subdomain pattern such as https://*.example.com, and that "*" is
+// not combined with credentials, which browsers reject
+func ValidateOrigins(origins []string, allowCredentials bool) error {
+	for _, origin := range origins {
+		if origin == "*" {
+			if allowCredentials {
+				return fmt.Errorf("origin \"*\" cannot be used with credentials; list the origins or disable CORS_ALLOW_CREDENTIALS")
+			}
+			continue
+		}
+		if err := ValidateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateOrigin checks that origin is scheme://host[:port], where host may
+// start with "*." to match any subdomain
+func ValidateOrigin(origin string) error {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("invalid origin %q: must be scheme://host[:port]", origin)
+	}
+
+	if parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return fmt.Errorf("invalid origin %q: must not include a path, query or credentials", origin)
+	}
+
+	host := parsed.Hostname()
+	if wildcard := strings.Count(host, "*"); wildcard > 0 {
+		domain, found := strings.CutPrefix(host, "*.")
+		if !found || wildcard > 1 || !strings.Contains(domain, ".") {
+			return fmt.Errorf("invalid origin %q: wildcards are only allowed as *.domain.tld", origin)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/utils"
 )
@@ -19,8 +20,15 @@ type DatabaseConfig struct {
 	MaxIdleConns int
 	MaxOpenConns int
 	LogLevel     string
+
+	// Query instrumentation
+	SlowQueryThreshold time.Duration // Queries slower than this are logged (0 disables)
+	LogQueryParams     bool          // Include parameter values in slow query logs and spans
 }
 
+// DefaultSlowQueryThreshold is the default slow query logging threshold
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
 // LoadDatabaseConfig loads database configuration from environment
 func LoadDatabaseConfig() DatabaseConfig {
 	return DatabaseConfig{
@@ -34,6 +42,9 @@ func LoadDatabaseConfig() DatabaseConfig {
 		MaxIdleConns: utils.GetEnvInt("POSTGRES_MAX_IDLE_CONNS", 10),
 		MaxOpenConns: utils.GetEnvInt("POSTGRES_MAX_OPEN_CONNS", 100),
 		LogLevel:     utils.GetEnv("POSTGRES_LOG_LEVEL", "silent"),
+
+		SlowQueryThreshold: utils.GetEnvDuration("POSTGRES_SLOW_QUERY_THRESHOLD", DefaultSlowQueryThreshold),
+		LogQueryParams:     utils.GetEnvBool("POSTGRES_LOG_QUERY_PARAMS", false),
 	}
 }
 
@@ -84,6 +95,10 @@ func (dc *DatabaseConfig) Validate() error {
 		return fmt.Errorf("max idle connections cannot exceed max open connections")
 	}
 
+	if dc.SlowQueryThreshold < 0 {
+		return fmt.Errorf("slow query threshold cannot be negative")
+	}
+
 	return nil
 }
 
@@ -18,8 +18,24 @@ type KeycloakConfig struct {
 	SkipPaths          []string
 	KeyRefreshInterval time.Duration
 	HTTPTimeout        time.Duration
+
+	// Client credentials used for service-to-service calls
+	ClientID     string
+	ClientSecret string
+
+	// Token introspection for opaque tokens
+	IntrospectionCacheTTL         time.Duration
+	IntrospectionNegativeCacheTTL time.Duration
+	IntrospectionOutagePolicy     string // fail_closed, fail_open or use_cached
 }
 
+// Introspection outage policies
+const (
+	IntrospectionFailClosed = "fail_closed"
+	IntrospectionFailOpen   = "fail_open"
+	IntrospectionUseCached  = "use_cached"
+)
+
 // LoadKeycloakConfig loads Keycloak configuration from environment
 func LoadKeycloakConfig() KeycloakConfig {
 	return KeycloakConfig{
@@ -30,6 +46,12 @@ func LoadKeycloakConfig() KeycloakConfig {
 		SkipPaths:          parseStringSlice(utils.GetEnv("KEYCLOAK_SKIP_PATHS", "/health,/metrics")),
 		KeyRefreshInterval: parseDuration(utils.GetEnv("KEYCLOAK_KEY_REFRESH_INTERVAL", "1h")),
 		HTTPTimeout:        parseDuration(utils.GetEnv("KEYCLOAK_HTTP_TIMEOUT", "10s")),
+		ClientID:           utils.GetEnv("KEYCLOAK_CLIENT_ID", ""),
+		ClientSecret:       utils.GetEnv("KEYCLOAK_CLIENT_SECRET", ""),
+
+		IntrospectionCacheTTL:         parseDuration(utils.GetEnv("KEYCLOAK_INTROSPECTION_CACHE_TTL", "1m")),
+		IntrospectionNegativeCacheTTL: parseDuration(utils.GetEnv("KEYCLOAK_INTROSPECTION_NEGATIVE_CACHE_TTL", "10s")),
+		IntrospectionOutagePolicy:     utils.GetEnv("KEYCLOAK_INTROSPECTION_OUTAGE_POLICY", IntrospectionFailClosed),
 	}
 }
 
@@ -51,6 +73,17 @@ func (kc *KeycloakConfig) Validate() error {
 		return fmt.Errorf("HTTP timeout must be positive")
 	}
 
+	if kc.ClientSecret != "" && kc.ClientID == "" {
+		return fmt.Errorf("keycloak client secret requires a client ID")
+	}
+
+	switch kc.IntrospectionOutagePolicy {
+	case "", IntrospectionFailClosed, IntrospectionFailOpen, IntrospectionUseCached:
+	default:
+		return fmt.Errorf("introspection outage policy must be one of %s, %s or %s",
+			IntrospectionFailClosed, IntrospectionFailOpen, IntrospectionUseCached)
+	}
+
 	return nil
 }
 
@@ -72,6 +105,27 @@ func (kc *KeycloakConfig) GetJWKSURL() string {
 	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", kc.URL, kc.Realm)
 }
 
+// HasClientCredentials returns true if a client ID and secret are set
+func (kc *KeycloakConfig) HasClientCredentials() bool {
+	return kc.ClientID != "" && kc.ClientSecret != ""
+}
+
+// GetTokenURL returns the token endpoint URL
+func (kc *KeycloakConfig) GetTokenURL() string {
+	if !kc.HasJWKS() {
+		return ""
+	}
+	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", kc.URL, kc.Realm)
+}
+
+// GetIntrospectionURL returns the token introspection endpoint URL
+func (kc *KeycloakConfig) GetIntrospectionURL() string {
+	if !kc.HasJWKS() {
+		return ""
+	}
+	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token/introspect", kc.URL, kc.Realm)
+}
+
 // ShouldSkipPath returns true if the path should skip authentication
 func (kc *KeycloakConfig) ShouldSkipPath(path string) bool {
 	for _, skipPath := range kc.SkipPaths {
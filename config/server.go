@@ -0,0 +1,137 @@
+// config/server.go
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+)
+
+// ServerConfig holds HTTP server configuration
+type ServerConfig struct {
+	// Timeouts of 0 use the defaults and negative values disable them. The
+	// read header timeout cannot be disabled.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	AdminPort         string // Separate port for operational endpoints (empty = disabled)
+
+	// Proxies whose Forwarded and X-Forwarded-* headers are trusted, as
+	// CIDRs or IPs (empty = none; clients are identified by their address)
+	TrustedProxies []string
+
+	// TLS settings (HTTPS is served when both cert and key are set)
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSMinVersion     string
+	TLSClientCAFile   string        // Enables mutual TLS when set
+	TLSReloadInterval time.Duration // How often certificate files are checked for changes
+}
+
+// Default server settings
+const (
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultWriteTimeout      = 15 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultMaxHeaderBytes    = 1 << 20
+	DefaultTLSReloadInterval = 1 * time.Minute
+)
+
+// tlsVersions maps configuration values to TLS protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// LoadServerConfig loads HTTP server configuration from environment
+func LoadServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadTimeout:       utils.GetEnvDuration("SERVER_READ_TIMEOUT", DefaultReadTimeout),
+		ReadHeaderTimeout: utils.GetEnvDuration("SERVER_READ_HEADER_TIMEOUT", DefaultReadHeaderTimeout),
+		WriteTimeout:      utils.GetEnvDuration("SERVER_WRITE_TIMEOUT", DefaultWriteTimeout),
+		IdleTimeout:       utils.GetEnvDuration("SERVER_IDLE_TIMEOUT", DefaultIdleTimeout),
+		MaxHeaderBytes:    utils.GetEnvInt("SERVER_MAX_HEADER_BYTES", DefaultMaxHeaderBytes),
+		AdminPort:         utils.GetEnv("ADMIN_PORT", ""),
+		TrustedProxies:    parseStringSlice(utils.GetEnv("TRUSTED_PROXIES", "")),
+		TLSCertFile:       utils.GetEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:        utils.GetEnv("TLS_KEY_FILE", ""),
+		TLSMinVersion:     utils.GetEnv("TLS_MIN_VERSION", "1.2"),
+		TLSClientCAFile:   utils.GetEnv("TLS_CLIENT_CA_FILE", ""),
+		TLSReloadInterval: utils.GetEnvDuration("TLS_RELOAD_INTERVAL", DefaultTLSReloadInterval),
+	}
+}
+
+// Validate validates the server configuration
+func (sc *ServerConfig) Validate() error {
+	if sc.ReadHeaderTimeout < 0 {
+		return fmt.Errorf("read header timeout cannot be disabled")
+	}
+
+	if sc.MaxHeaderBytes < 0 {
+		return fmt.Errorf("max header bytes cannot be negative")
+	}
+
+	if sc.AdminPort != "" && !utils.IsValidPort(sc.AdminPort) {
+		return fmt.Errorf("admin port must be a valid port number")
+	}
+
+	if _, err := utils.ParseCIDRs(sc.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted proxies: %w", err)
+	}
+
+	if (sc.TLSCertFile == "") != (sc.TLSKeyFile == "") {
+		return fmt.Errorf("TLS cert file and key file must be set together")
+	}
+
+	if sc.TLSClientCAFile != "" && !sc.IsTLSEnabled() {
+		return fmt.Errorf("TLS client CA requires TLS cert and key files")
+	}
+
+	if sc.TLSMinVersion != "" {
+		if _, ok := tlsVersions[sc.TLSMinVersion]; !ok {
+			return fmt.Errorf("unsupported TLS min version: %s", sc.TLSMinVersion)
+		}
+	}
+
+	for _, file := range []string{sc.TLSCertFile, sc.TLSKeyFile, sc.TLSClientCAFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("TLS file not readable: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IsTLSEnabled returns true if the server should serve HTTPS
+func (sc *ServerConfig) IsTLSEnabled() bool {
+	return sc.TLSCertFile != "" && sc.TLSKeyFile != ""
+}
+
+// IsAdminEnabled returns true if the admin server should be started
+func (sc *ServerConfig) IsAdminEnabled() bool {
+	return sc.AdminPort != ""
+}
+
+// IsMutualTLS returns true if client certificates are required
+func (sc *ServerConfig) IsMutualTLS() bool {
+	return sc.IsTLSEnabled() && sc.TLSClientCAFile != ""
+}
+
+// GetTLSMinVersion returns the minimum TLS version (defaults to TLS 1.2)
+func (sc *ServerConfig) GetTLSMinVersion() uint16 {
+	if version, ok := tlsVersions[sc.TLSMinVersion]; ok {
+		return version
+	}
+	return tls.VersionTLS12
+}
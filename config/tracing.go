@@ -0,0 +1,83 @@
+// config/tracing.go
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+)
+
+// TracingConfig holds distributed tracing configuration
+type TracingConfig struct {
+	Enabled       bool
+	OTLPEndpoint  string            // OTLP/HTTP base URL, e.g. http://otel-collector:4318 (empty = spans are not exported)
+	OTLPHeaders   map[string]string // Extra headers sent with each export, e.g. for authentication
+	SampleRatio   float64           // Fraction of new traces that are sampled (0-1)
+	ExportTimeout time.Duration
+	BatchSize     int
+	BatchTimeout  time.Duration
+}
+
+// Default tracing settings
+const (
+	DefaultTracingSampleRatio   = 1.0
+	DefaultTracingExportTimeout = 10 * time.Second
+	DefaultTracingBatchSize     = 512
+	DefaultTracingBatchTimeout  = 5 * time.Second
+)
+
+// LoadTracingConfig loads tracing configuration from environment
+func LoadTracingConfig() TracingConfig {
+	return TracingConfig{
+		Enabled:       utils.GetEnvBool("TRACING_ENABLED", false),
+		OTLPEndpoint:  utils.GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTLPHeaders:   parseKeyValues(utils.GetEnv("OTEL_EXPORTER_OTLP_HEADERS", "")),
+		SampleRatio:   utils.GetEnvFloat("TRACING_SAMPLE_RATIO", DefaultTracingSampleRatio),
+		ExportTimeout: utils.GetEnvDuration("TRACING_EXPORT_TIMEOUT", DefaultTracingExportTimeout),
+		BatchSize:     utils.GetEnvInt("TRACING_BATCH_SIZE", DefaultTracingBatchSize),
+		BatchTimeout:  utils.GetEnvDuration("TRACING_BATCH_TIMEOUT", DefaultTracingBatchTimeout),
+	}
+}
+
+// Validate validates the tracing configuration
+func (tc *TracingConfig) Validate() error {
+	if tc.SampleRatio < 0 || tc.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+
+	if tc.ExportTimeout < 0 || tc.BatchTimeout < 0 {
+		return fmt.Errorf("tracing timeouts cannot be negative")
+	}
+
+	if tc.BatchSize < 0 {
+		return fmt.Errorf("tracing batch size cannot be negative")
+	}
+
+	if tc.OTLPEndpoint != "" && !utils.IsValidURL(tc.OTLPEndpoint) {
+		return fmt.Errorf("OTLP endpoint must be a valid URL")
+	}
+
+	return nil
+}
+
+// IsExportEnabled returns true if spans should be exported over OTLP
+func (tc *TracingConfig) IsExportEnabled() bool {
+	return tc.Enabled && tc.OTLPEndpoint != ""
+}
+
+// parseKeyValues parses a comma-separated list of key=value pairs
+func parseKeyValues(value string) map[string]string {
+	result := make(map[string]string)
+
+	for _, pair := range parseStringSlice(value) {
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	return result
+}
@@ -97,6 +97,24 @@ func (v *Validator) ValidateURL(field, value string) *Validator {
 	return v
 }
 
+// ValidateOrigin validates that a value is "*" or a CORS origin, optionally
+// with a wildcard subdomain
+func (v *Validator) ValidateOrigin(field, value string) *Validator {
+	if value == "" || value == "*" {
+		return v
+	}
+
+	if err := ValidateOrigin(value); err != nil {
+		v.errors = append(v.errors, ValidationError{
+			Field:   field,
+			Value:   value,
+			Message: err.Error(),
+		})
+	}
+
+	return v
+}
+
 // ValidateOneOf validates that a value is one of the allowed values
 func (v *Validator) ValidateOneOf(field, value string, allowed []string) *Validator {
 	if value == "" {
@@ -176,14 +194,18 @@ func ValidateConfig(config *Config) error {
 	// Validate basic fields
 	validator.ValidateRequired("PORT", config.Port)
 	validator.ValidatePort("PORT", config.Port)
+	validator.ValidatePort("ADMIN_PORT", config.ServerConfig.AdminPort)
 	validator.ValidateRequired("SERVICE_NAME", config.ServiceName)
 	validator.ValidateOneOf("ENVIRONMENT", config.Environment, []string{"dev", "development", "staging", "prod", "production"})
 	validator.ValidateOneOf("LOG_LEVEL", config.LogLevel, []string{"debug", "info", "warn", "error"})
+	validator.ValidateOneOf("LOG_FORMAT", config.LogFormat, []string{"json", "text"})
+	validator.ValidateOneOf("LOG_BODIES", config.LogBodies, []string{"true", "false"})
+	validator.ValidateOneOf("TLS_MIN_VERSION", config.ServerConfig.TLSMinVersion, []string{"1.0", "1.1", "1.2", "1.3"})
 
 	// Validate allowed origins
 	for i, origin := range config.AllowedOrigins {
 		field := fmt.Sprintf("ALLOWED_ORIGINS[%d]", i)
-		validator.ValidateURL(field, origin)
+		validator.ValidateOrigin(field, origin)
 	}
 
 	return validator.Error()
@@ -2,9 +2,11 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
 	"github.com/JorgeSaicoski/microservice-commons/utils"
 	"github.com/JorgeSaicoski/pgconnect"
 	"gorm.io/gorm/logger"
@@ -56,20 +58,32 @@ func (cm *ConnectionManager) Connect() (*pgconnect.DB, error) {
 	maxRetries := utils.GetEnvInt("DB_MAX_RETRIES", 3)
 	retryDelay := time.Duration(utils.GetEnvInt("DB_RETRY_DELAY_SECONDS", 30)) * time.Second
 
+	logger := logging.Default().With(
+		slog.String("component", "database"),
+		slog.String("host", cm.config.Host),
+		slog.String("database", cm.config.DatabaseName),
+	)
+
 	var err error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		fmt.Printf("Attempting to connect to database (attempt %d of %d)\n", attempt, maxRetries)
+		logger.Info("connecting to database", slog.Int("attempt", attempt), slog.Int("max_attempts", maxRetries))
 
 		cm.db, err = pgconnect.New(pgConfig)
 		if err == nil {
-			fmt.Println("Successfully connected to database")
+			logger.Info("connected to database")
+
+			// Query spans, latency metrics and slow query logging
+			if err := cm.db.Use(NewQueryInstrumentation(InstrumentationConfigFromConfig(cm.config))); err != nil {
+				logger.Warn("failed to register query instrumentation", logging.Err(err))
+			}
+
 			return cm.db, nil
 		}
 
-		fmt.Printf("Failed to connect to database: %v\n", err)
+		logger.Warn("failed to connect to database", logging.Err(err))
 
 		if attempt < maxRetries {
-			fmt.Printf("Retrying in %v...\n", retryDelay)
+			logger.Info("retrying database connection", slog.Duration("delay", retryDelay))
 			time.Sleep(retryDelay)
 		}
 	}
@@ -0,0 +1,147 @@
+// database/idempotency.go
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/pgconnect"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey is a claimed idempotency key and its stored response
+type IdempotencyKey struct {
+	Key         string    `gorm:"primaryKey"`
+	RequestHash string    `gorm:"not null"`
+	Completed   bool      `gorm:"not null"`
+	Status      int       `gorm:"not null"`
+	Headers     string    `gorm:"type:jsonb;not null"`
+	Body        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+// TableName implements gorm.Tabler
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyStore is a middleware.IdempotencyStore backed by Postgres, so
+// retries are recognised whichever replica they reach
+type IdempotencyStore struct {
+	db *pgconnect.DB
+}
+
+// NewIdempotencyStore creates a Postgres idempotency store
+func NewIdempotencyStore(db *pgconnect.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// Migrate creates the idempotency key table
+func (s *IdempotencyStore) Migrate() error {
+	return s.db.AutoMigrate(&IdempotencyKey{})
+}
+
+// Begin implements middleware.IdempotencyStore. Expired keys are claimed
+// again as if new.
+func (s *IdempotencyStore) Begin(ctx context.Context, key, requestHash string, lockTimeout time.Duration) (*middleware.IdempotencyRecord, bool, error) {
+	db := s.db.WithContext(ctx)
+
+	// A key deleted between the insert and the read is claimed on the retry
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+
+		var claimed string
+		result := db.Raw(`
+			INSERT INTO idempotency_keys (key, request_hash, completed, status, headers, body, created_at, expires_at)
+			VALUES (CAST(? AS text), CAST(? AS text), false, 0, '{}', NULL, CAST(? AS timestamptz), CAST(? AS timestamptz))
+			ON CONFLICT (key) DO UPDATE
+				SET request_hash = excluded.request_hash, completed = false, status = 0, headers = '{}',
+					body = NULL, created_at = excluded.created_at, expires_at = excluded.expires_at
+				WHERE idempotency_keys.expires_at <= excluded.created_at
+			RETURNING key`,
+			key, requestHash, now, now.Add(lockTimeout),
+		).Scan(&claimed)
+		if result.Error != nil {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return &middleware.IdempotencyRecord{RequestHash: requestHash}, true, nil
+		}
+
+		var row IdempotencyKey
+		err := db.Where("key = ?", key).Take(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read idempotency key: %w", err)
+		}
+
+		record, err := row.record()
+		return record, false, err
+	}
+
+	return nil, false, fmt.Errorf("failed to claim idempotency key: concurrent updates")
+}
+
+// Complete implements middleware.IdempotencyStore
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, response middleware.IdempotencyResponse, ttl time.Duration) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response headers: %w", err)
+	}
+
+	err = s.db.WithContext(ctx).Model(&IdempotencyKey{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{
+			"completed":  true,
+			"status":     response.Status,
+			"headers":    string(headers),
+			"body":       response.Body,
+			"expires_at": time.Now().Add(ttl),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release implements middleware.IdempotencyStore
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).
+		Where("key = ? AND completed = ?", key, false).
+		Delete(&IdempotencyKey{}).Error
+}
+
+// PurgeExpired deletes expired keys
+func (s *IdempotencyStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+// StartCleanup purges expired keys every interval until ctx is cancelled
+func (s *IdempotencyStore) StartCleanup(ctx context.Context, interval time.Duration) {
+	go runCleanup(ctx, interval, "idempotency_keys", s.PurgeExpired)
+}
+
+// record converts the row to a middleware.IdempotencyRecord
+func (k *IdempotencyKey) record() (*middleware.IdempotencyRecord, error) {
+	record := &middleware.IdempotencyRecord{
+		RequestHash: k.RequestHash,
+		Completed:   k.Completed,
+	}
+	if !k.Completed {
+		return record, nil
+	}
+
+	record.Response = middleware.IdempotencyResponse{Status: k.Status, Body: k.Body}
+	if err := json.Unmarshal([]byte(k.Headers), &record.Response.Header); err != nil {
+		return nil, fmt.Errorf("failed to decode response headers: %w", err)
+	}
+	return record, nil
+}
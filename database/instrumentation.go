@@ -0,0 +1,187 @@
+package database
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/metrics"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"gorm.io/gorm"
+)
+
+// QueryDurationMetric is the expvar name of the query latency histograms,
+// keyed by "operation:table"
+const QueryDurationMetric = "db_query_duration_ms"
+
+// instrumentationStateKey stores per-statement state between callbacks
+const instrumentationStateKey = "microservice-commons:instrumentation"
+
+// InstrumentationConfig holds configuration for query instrumentation
+type InstrumentationConfig struct {
+	SlowQueryThreshold time.Duration   // Queries slower than this are logged at warn level (0 disables)
+	LogQueryParams     bool            // Include parameter values in slow query logs and spans
+	Tracer             *tracing.Tracer // Tracer for query spans (default: tracing.Default())
+}
+
+// InstrumentationConfigFromConfig builds instrumentation configuration
+// from the database configuration
+func InstrumentationConfigFromConfig(cfg config.DatabaseConfig) InstrumentationConfig {
+	return InstrumentationConfig{
+		SlowQueryThreshold: cfg.SlowQueryThreshold,
+		LogQueryParams:     cfg.LogQueryParams,
+	}
+}
+
+// QueryInstrumentation is a GORM plugin that records a span, a latency
+// observation and, for slow queries, a log line for every query. Queries
+// are correlated with the request when run with db.WithContext(ctx).
+type QueryInstrumentation struct {
+	config    InstrumentationConfig
+	durations *metrics.HistogramVec
+}
+
+// NewQueryInstrumentation creates the query instrumentation plugin
+func NewQueryInstrumentation(config InstrumentationConfig) *QueryInstrumentation {
+	return &QueryInstrumentation{
+		config:    config,
+		durations: metrics.NewHistogramVec(QueryDurationMetric, metrics.DefaultLatencyBuckets),
+	}
+}
+
+// Name implements gorm.Plugin
+func (qi *QueryInstrumentation) Name() string {
+	return "microservice-commons:instrumentation"
+}
+
+// Initialize implements gorm.Plugin, registering callbacks around every
+// GORM operation
+func (qi *QueryInstrumentation) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	name := qi.Name()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register(name+":before_create", qi.before),
+		callbacks.Create().After("gorm:create").Register(name+":after_create", qi.after("insert")),
+		callbacks.Query().Before("gorm:query").Register(name+":before_query", qi.before),
+		callbacks.Query().After("gorm:query").Register(name+":after_query", qi.after("select")),
+		callbacks.Update().Before("gorm:update").Register(name+":before_update", qi.before),
+		callbacks.Update().After("gorm:update").Register(name+":after_update", qi.after("update")),
+		callbacks.Delete().Before("gorm:delete").Register(name+":before_delete", qi.before),
+		callbacks.Delete().After("gorm:delete").Register(name+":after_delete", qi.after("delete")),
+		callbacks.Row().Before("gorm:row").Register(name+":before_row", qi.before),
+		callbacks.Row().After("gorm:row").Register(name+":after_row", qi.after("select")),
+		callbacks.Raw().Before("gorm:raw").Register(name+":before_raw", qi.before),
+		callbacks.Raw().After("gorm:raw").Register(name+":after_raw", qi.after("raw")),
+	)
+}
+
+// queryState is carried from the before to the after callback
+type queryState struct {
+	start time.Time
+	span  *tracing.Span
+}
+
+// before starts the span and timer for a statement
+func (qi *QueryInstrumentation) before(db *gorm.DB) {
+	tracer := qi.config.Tracer
+	if tracer == nil {
+		tracer = tracing.Default()
+	}
+
+	_, span := tracer.Start(db.Statement.Context, "db.query", tracing.SpanKindClient)
+	db.InstanceSet(instrumentationStateKey, &queryState{start: time.Now(), span: span})
+}
+
+// after finishes the span, records latency and logs slow queries
+func (qi *QueryInstrumentation) after(defaultOperation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(instrumentationStateKey)
+		if !ok {
+			return
+		}
+		state, ok := value.(*queryState)
+		if !ok {
+			return
+		}
+
+		duration := time.Since(state.start)
+		sql := db.Statement.SQL.String()
+		operation := queryOperation(sql, defaultOperation)
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		qi.durations.Observe(operation+":"+table, float64(duration.Microseconds())/1000)
+
+		span := state.span
+		span.SetName(strings.ToUpper(operation) + " " + table)
+		span.SetAttribute("db.system", "postgresql")
+		span.SetAttribute("db.operation", operation)
+		span.SetAttribute("db.sql.table", table)
+		span.SetAttribute("db.rows_affected", db.Statement.RowsAffected)
+		span.SetAttribute("db.statement", qi.statement(db, sql))
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+		}
+		span.End()
+
+		if qi.config.SlowQueryThreshold > 0 && duration > qi.config.SlowQueryThreshold {
+			qi.logSlowQuery(db, sql, operation, table, duration)
+		}
+	}
+}
+
+// logSlowQuery logs a query that exceeded the slow query threshold
+func (qi *QueryInstrumentation) logSlowQuery(db *gorm.DB, sql, operation, table string, duration time.Duration) {
+	ctx := db.Statement.Context
+	logger := logging.FromContext(ctx)
+
+	attrs := []any{
+		slog.String("component", "database"),
+		slog.String("operation", operation),
+		slog.String("table", table),
+		slog.Duration("duration", duration),
+		slog.Duration("threshold", qi.config.SlowQueryThreshold),
+		slog.Int64("rows_affected", db.Statement.RowsAffected),
+		slog.String("statement", qi.statement(db, sql)),
+	}
+
+	// The request-scoped logger already carries the request ID
+	if requestID := logging.RequestIDFromContext(ctx); requestID != "" && logger == logging.Default() {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if db.Error != nil {
+		attrs = append(attrs, logging.Err(db.Error))
+	}
+
+	logger.WarnContext(ctx, "slow query", attrs...)
+}
+
+// statement returns the SQL to record, with parameter values only when
+// explicitly enabled
+func (qi *QueryInstrumentation) statement(db *gorm.DB, sql string) string {
+	if qi.config.LogQueryParams && len(db.Statement.Vars) > 0 {
+		return db.Dialector.Explain(sql, db.Statement.Vars...)
+	}
+	return sql
+}
+
+// queryOperation derives the operation from the SQL verb, falling back to
+// the GORM callback that ran
+func queryOperation(sql, fallback string) string {
+	verb, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+
+	switch verb = strings.ToLower(verb); verb {
+	case "":
+		return fallback
+	case "with":
+		return "select"
+	default:
+		return verb
+	}
+}
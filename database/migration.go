@@ -3,7 +3,9 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
 
+	"github.com/JorgeSaicoski/microservice-commons/logging"
 	"github.com/JorgeSaicoski/pgconnect"
 )
 
@@ -51,18 +53,20 @@ func (m *Migrator) Migrate() error {
 		return fmt.Errorf("no models to migrate")
 	}
 
+	logger := logging.Default().With(slog.String("component", "migration"))
+
 	if m.options.Verbose {
-		fmt.Printf("Starting migration for %d models...\n", len(m.models))
+		logger.Info("starting migration", slog.Int("models", len(m.models)))
 	}
 
 	// Drop tables if requested (use with extreme caution!)
 	if m.options.DropTables {
 		if m.options.Verbose {
-			fmt.Println("WARNING: Dropping existing tables...")
+			logger.Warn("dropping existing tables")
 		}
 		for _, model := range m.models {
 			if err := m.db.DB.Migrator().DropTable(model); err != nil {
-				fmt.Printf("Warning: Failed to drop table for model %T: %v\n", model, err)
+				logger.Warn("failed to drop table", slog.String("model", fmt.Sprintf("%T", model)), logging.Err(err))
 			}
 		}
 	}
@@ -73,13 +77,13 @@ func (m *Migrator) Migrate() error {
 	}
 
 	if m.options.Verbose {
-		fmt.Println("Migration completed successfully")
+		logger.Info("migration completed")
 	}
 
 	// Create indexes if requested
 	if m.options.CreateIndexes {
 		if err := m.createIndexes(); err != nil {
-			fmt.Printf("Warning: Failed to create some indexes: %v\n", err)
+			logger.Warn("failed to create some indexes", logging.Err(err))
 		}
 	}
 
@@ -91,8 +95,10 @@ func (m *Migrator) createIndexes() error {
 	// Add common indexes here based on your models
 	// This is where you'd add indexes that are common across services
 
+	logger := logging.Default().With(slog.String("component", "migration"))
+
 	if m.options.Verbose {
-		fmt.Println("Creating additional indexes...")
+		logger.Info("creating additional indexes")
 	}
 
 	// Example indexes - customize based on your needs
@@ -111,7 +117,7 @@ func (m *Migrator) createIndexes() error {
 
 	for _, indexSQL := range indexes {
 		if err := m.db.DB.Exec(indexSQL).Error; err != nil {
-			fmt.Printf("Warning: Failed to create index: %s - %v\n", indexSQL, err)
+			logger.Warn("failed to create index", slog.String("sql", indexSQL), logging.Err(err))
 		}
 	}
 
@@ -132,7 +138,7 @@ func UnsafeMigrate(db *pgconnect.DB, models ...interface{}) error {
 		Verbose:       true,
 	}
 
-	fmt.Println("⚠️  WARNING: This will DROP ALL TABLES! ⚠️")
+	logging.Default().Warn("unsafe migration will drop all tables", slog.String("component", "migration"))
 
 	migrator := NewMigrator(db, options)
 	return migrator.AddModels(models...).Migrate()
@@ -0,0 +1,249 @@
+// database/quota.go
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/JorgeSaicoski/microservice-commons/types"
+	"github.com/JorgeSaicoski/pgconnect"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// QuotaPeriod is the period a quota resets over
+type QuotaPeriod string
+
+const (
+	QuotaDaily   QuotaPeriod = "daily"
+	QuotaMonthly QuotaPeriod = "monthly"
+)
+
+// Start returns the start of the period containing t, in UTC
+func (p QuotaPeriod) Start(t time.Time) time.Time {
+	t = t.UTC()
+	if p == QuotaMonthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// End returns the start of the period after the one containing t
+func (p QuotaPeriod) End(t time.Time) time.Time {
+	start := p.Start(t)
+	if p == QuotaMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// APIKeyQuota limits the requests an API key may make per period
+type APIKeyQuota struct {
+	APIKeyID uint         `json:"api_key_id" gorm:"primaryKey"`
+	APIKey   types.APIKey `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Period   QuotaPeriod  `json:"period" gorm:"primaryKey;type:varchar(16)"`
+	Limit    int64        `json:"limit" gorm:"column:quota_limit;not null"`
+}
+
+// TableName implements gorm.Tabler
+func (APIKeyQuota) TableName() string {
+	return "api_key_quotas"
+}
+
+// APIKeyUsage is an API key's usage in one period
+type APIKeyUsage struct {
+	APIKeyID    uint        `json:"api_key_id" gorm:"primaryKey"`
+	Period      QuotaPeriod `json:"period" gorm:"primaryKey;type:varchar(16)"`
+	PeriodStart time.Time   `json:"period_start" gorm:"primaryKey"`
+	Count       int64       `json:"count" gorm:"not null"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// TableName implements gorm.Tabler
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
+
+// QuotaResult is the outcome of consuming quota. Limit is 0 when the key
+// has no quota.
+type QuotaResult struct {
+	Allowed   bool
+	Period    QuotaPeriod // The most constrained period
+	Limit     int64
+	Used      int64
+	Remaining int64
+	ResetAt   time.Time
+}
+
+// errQuotaExceeded rolls back a consumption that exceeded a quota
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaStore tracks daily and monthly quotas per API key in Postgres
+type QuotaStore struct {
+	db *pgconnect.DB
+}
+
+// NewQuotaStore creates a Postgres quota store
+func NewQuotaStore(db *pgconnect.DB) *QuotaStore {
+	return &QuotaStore{db: db}
+}
+
+// Migrate creates the quota tables. types.APIKey must already be migrated.
+func (s *QuotaStore) Migrate() error {
+	return s.db.AutoMigrate(&APIKeyQuota{}, &APIKeyUsage{})
+}
+
+// SetQuota sets an API key's limit for a period
+func (s *QuotaStore) SetQuota(ctx context.Context, apiKeyID uint, period QuotaPeriod, limit int64) error {
+	quota := APIKeyQuota{APIKeyID: apiKeyID, Period: period, Limit: limit}
+	err := s.db.WithContext(ctx).Omit("APIKey").Save(&quota).Error
+	if err != nil {
+		return fmt.Errorf("failed to set quota: %w", err)
+	}
+	return nil
+}
+
+// RemoveQuota removes an API key's limit for a period
+func (s *QuotaStore) RemoveQuota(ctx context.Context, apiKeyID uint, period QuotaPeriod) error {
+	return s.db.WithContext(ctx).
+		Where("api_key_id = ? AND period = ?", apiKeyID, period).
+		Delete(&APIKeyQuota{}).Error
+}
+
+// Consume records n requests for the API key. Usage is only recorded if it
+// stays within every quota set for the key; keys without quotas are always
+// allowed and their usage is still recorded.
+func (s *QuotaStore) Consume(ctx context.Context, apiKeyID uint, n int64) (QuotaResult, error) {
+	now := time.Now()
+	result := QuotaResult{Allowed: true}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quotas []APIKeyQuota
+		if err := tx.Where("api_key_id = ?", apiKeyID).Find(&quotas).Error; err != nil {
+			return fmt.Errorf("failed to load quotas: %w", err)
+		}
+		limits := make(map[QuotaPeriod]int64, len(quotas))
+		for _, quota := range quotas {
+			limits[quota.Period] = quota.Limit
+		}
+
+		for _, period := range []QuotaPeriod{QuotaDaily, QuotaMonthly} {
+			limit, limited := limits[period]
+			if !limited {
+				limit = -1
+			}
+
+			var used int64
+			update := tx.Raw(`
+				INSERT INTO api_key_usage (api_key_id, period, period_start, count, updated_at)
+				SELECT CAST(? AS bigint), CAST(? AS varchar), CAST(? AS timestamptz), CAST(? AS bigint), CAST(? AS timestamptz)
+				WHERE CAST(? AS bigint) < 0 OR CAST(? AS bigint) <= CAST(? AS bigint)
+				ON CONFLICT (api_key_id, period, period_start) DO UPDATE
+					SET count = api_key_usage.count + excluded.count, updated_at = excluded.updated_at
+					WHERE CAST(? AS bigint) < 0 OR api_key_usage.count + excluded.count <= CAST(? AS bigint)
+				RETURNING count`,
+				apiKeyID, period, period.Start(now), n, now, limit, n, limit,
+				limit, limit,
+			).Scan(&used)
+			if update.Error != nil {
+				return fmt.Errorf("failed to record usage: %w", update.Error)
+			}
+
+			if !limited {
+				continue
+			}
+
+			if update.RowsAffected == 0 {
+				if err := tx.Model(&APIKeyUsage{}).Select("count").
+					Where("api_key_id = ? AND period = ? AND period_start = ?", apiKeyID, period, period.Start(now)).
+					Scan(&used).Error; err != nil {
+					return fmt.Errorf("failed to read usage: %w", err)
+				}
+				result = QuotaResult{Period: period, Limit: limit, Used: used, Remaining: max(0, limit-used), ResetAt: period.End(now)}
+				return errQuotaExceeded
+			}
+
+			// Report the period with the least headroom
+			if remaining := limit - used; result.Limit == 0 || remaining < result.Remaining {
+				result = QuotaResult{Allowed: true, Period: period, Limit: limit, Used: used, Remaining: remaining, ResetAt: period.End(now)}
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errQuotaExceeded) {
+		return result, nil
+	}
+	return result, err
+}
+
+// Usage returns an API key's usage for periods starting in [from, to)
+func (s *QuotaStore) Usage(ctx context.Context, apiKeyID uint, period QuotaPeriod, from, to time.Time) ([]APIKeyUsage, error) {
+	var usage []APIKeyUsage
+	err := s.db.WithContext(ctx).
+		Where("api_key_id = ? AND period = ? AND period_start >= ? AND period_start < ?", apiKeyID, period, from, to).
+		Order("period_start").
+		Find(&usage).Error
+	return usage, err
+}
+
+// UsageForPeriod returns every API key's usage in the period containing t,
+// highest first
+func (s *QuotaStore) UsageForPeriod(ctx context.Context, period QuotaPeriod, t time.Time) ([]APIKeyUsage, error) {
+	var usage []APIKeyUsage
+	err := s.db.WithContext(ctx).
+		Where("period = ? AND period_start = ?", period, period.Start(t)).
+		Order("count DESC").
+		Find(&usage).Error
+	return usage, err
+}
+
+// PurgeUsageBefore deletes usage for periods starting before t
+func (s *QuotaStore) PurgeUsageBefore(ctx context.Context, t time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("period_start < ?", t).Delete(&APIKeyUsage{})
+	return result.RowsAffected, result.Error
+}
+
+// QuotaMiddleware consumes one request of quota for the API key resolved
+// by apiKeyID, rejecting the request with 429 once a quota is exhausted.
+// Requests without an API key pass through; store errors fail open.
+func QuotaMiddleware(store *QuotaStore, apiKeyID func(*gin.Context) (uint, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := apiKeyID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := store.Consume(c.Request.Context(), id, 1)
+		if err != nil {
+			middleware.Logger(c).Warn("quota check failed", logging.Err(err))
+			c.Next()
+			return
+		}
+
+		if result.Limit > 0 {
+			c.Header("X-Quota-Limit", strconv.FormatInt(result.Limit, 10))
+			c.Header("X-Quota-Remaining", strconv.FormatInt(result.Remaining, 10))
+			c.Header("X-Quota-Reset", result.ResetAt.Format(http.TimeFormat))
+		}
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(result.ResetAt).Seconds())+1))
+			responses.Error(c, http.StatusTooManyRequests, responses.ErrCodeTooManyRequests,
+				fmt.Sprintf("%s quota exceeded", result.Period))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
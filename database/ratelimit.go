@@ -0,0 +1,139 @@
+// database/ratelimit.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/pgconnect"
+)
+
+// RateLimitCounter counts requests for a key in one fixed window
+type RateLimitCounter struct {
+	Key         string    `gorm:"primaryKey"`
+	WindowStart time.Time `gorm:"primaryKey"`
+	Count       int       `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+// TableName implements gorm.Tabler
+func (RateLimitCounter) TableName() string {
+	return "rate_limit_counters"
+}
+
+// RateLimitStore is a middleware.RateLimitStore backed by Postgres, so every
+// replica enforces the same limits. Requests are counted with atomic upserts
+// per fixed window and limits are enforced as a sliding window over the
+// current and previous windows, whatever the configured algorithm.
+type RateLimitStore struct {
+	db *pgconnect.DB
+}
+
+// NewRateLimitStore creates a Postgres rate limit store
+func NewRateLimitStore(db *pgconnect.DB) *RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+// Migrate creates the rate limit table
+func (s *RateLimitStore) Migrate() error {
+	return s.db.AutoMigrate(&RateLimitCounter{})
+}
+
+// Take implements middleware.RateLimitStore
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit middleware.RateLimit) (middleware.RateLimitResult, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+	start := now.Truncate(limit.Window)
+	limit.Algorithm = middleware.SlidingWindow
+
+	var previous int
+	err := db.Model(&RateLimitCounter{}).
+		Select("count").
+		Where("key = ? AND window_start = ?", key, start.Add(-limit.Window)).
+		Scan(&previous).Error
+	if err != nil {
+		return middleware.RateLimitResult{}, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+
+	// The most requests the current window may hold given the previous one
+	weight := 1 - float64(now.Sub(start))/float64(limit.Window)
+	capacity := int(math.Floor(float64(limit.Requests) - float64(previous)*weight))
+
+	current := 0
+	if capacity > 0 {
+		result := db.Raw(`
+			INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+			VALUES (CAST(? AS text), CAST(? AS timestamptz), 1, CAST(? AS timestamptz))
+			ON CONFLICT (key, window_start) DO UPDATE
+				SET count = rate_limit_counters.count + 1
+				WHERE rate_limit_counters.count < CAST(? AS bigint)
+			RETURNING count`,
+			key, start, start.Add(2*limit.Window), capacity,
+		).Scan(&current)
+		if result.Error != nil {
+			return middleware.RateLimitResult{}, fmt.Errorf("failed to update rate limit counter: %w", result.Error)
+		}
+
+		if result.RowsAffected > 0 {
+			// Replay the counted request on the equivalent state for headers
+			state := middleware.RateLimitState{WindowStart: start, Count: current - 1, PrevCount: previous}
+			return state.Take(limit, now), nil
+		}
+	}
+
+	// Denied: report against the current count without incrementing it
+	err = db.Model(&RateLimitCounter{}).
+		Select("count").
+		Where("key = ? AND window_start = ?", key, start).
+		Scan(&current).Error
+	if err != nil {
+		return middleware.RateLimitResult{}, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+
+	state := middleware.RateLimitState{WindowStart: start, Count: max(current, capacity), PrevCount: previous}
+	return state.Take(limit, now), nil
+}
+
+// PurgeExpired deletes counters no longer needed by any window
+func (s *RateLimitStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
+
+// StartCleanup purges expired counters every interval until ctx is cancelled
+func (s *RateLimitStore) StartCleanup(ctx context.Context, interval time.Duration) {
+	go runCleanup(ctx, interval, "rate_limit_counters", s.PurgeExpired)
+}
+
+// runCleanup calls purge every interval until ctx is cancelled
+func runCleanup(ctx context.Context, interval time.Duration, table string, purge func(context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := purge(ctx)
+			if err != nil {
+				logging.Default().Warn("failed to purge expired rows",
+					slog.String("table", table),
+					logging.Err(err),
+				)
+				continue
+			}
+			if deleted > 0 {
+				logging.Default().Debug("purged expired rows",
+					slog.String("table", table),
+					slog.Int64("deleted", deleted),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// database/revocation.go
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/pgconnect"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevocationChannel is the NOTIFY channel revocations are published on
+const RevocationChannel = "token_revocations"
+
+// RevokedToken is a token revoked by ID
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt time.Time `gorm:"not null"`
+}
+
+// TableName implements gorm.Tabler
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// RevokedSubject holds the cutoff before which a subject's tokens are revoked
+type RevokedSubject struct {
+	Subject       string    `gorm:"primaryKey"`
+	RevokedBefore time.Time `gorm:"not null"`
+}
+
+// TableName implements gorm.Tabler
+func (RevokedSubject) TableName() string {
+	return "revoked_subjects"
+}
+
+// RevocationSink receives revocations published by other replicas.
+// auth.MemoryRevocationStore implements it.
+type RevocationSink interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeSubject(ctx context.Context, subject string, before time.Time) error
+}
+
+// revocationEvent is the NOTIFY payload
+type revocationEvent struct {
+	TokenID   string    `json:"token_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Subject   string    `json:"subject,omitempty"`
+	Before    time.Time `json:"before"`
+}
+
+// RevocationStore persists revocations in Postgres and publishes them with
+// NOTIFY. It implements auth.RevocationStore and can be used directly as a
+// middleware.RevocationChecker, at the cost of a query per request, or
+// paired with ListenForRevocations and an in-memory store.
+type RevocationStore struct {
+	db *pgconnect.DB
+}
+
+// NewRevocationStore creates a Postgres revocation store
+func NewRevocationStore(db *pgconnect.DB) *RevocationStore {
+	return &RevocationStore{db: db}
+}
+
+// Migrate creates the revocation tables
+func (s *RevocationStore) Migrate() error {
+	return s.db.AutoMigrate(&RevokedToken{}, &RevokedSubject{})
+}
+
+// RevokeToken records a revoked token and notifies listeners
+func (s *RevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return errors.New("token ID is required")
+	}
+
+	record := RevokedToken{TokenID: tokenID, ExpiresAt: expiresAt, RevokedAt: time.Now()}
+	if record.ExpiresAt.IsZero() {
+		record.ExpiresAt = record.RevokedAt.Add(24 * time.Hour)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": gorm.Expr("GREATEST(revoked_tokens.expires_at, excluded.expires_at)")}),
+		}).Create(&record).Error
+		if err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+		return notify(tx, revocationEvent{TokenID: tokenID, ExpiresAt: record.ExpiresAt})
+	})
+}
+
+// RevokeSubject revokes a subject's tokens issued before the cutoff and
+// notifies listeners
+func (s *RevocationStore) RevokeSubject(ctx context.Context, subject string, before time.Time) error {
+	if subject == "" {
+		return errors.New("subject is required")
+	}
+
+	record := RevokedSubject{Subject: subject, RevokedBefore: before.Truncate(time.Second)}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subject"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"revoked_before": gorm.Expr("GREATEST(revoked_subjects.revoked_before, excluded.revoked_before)")}),
+		}).Create(&record).Error
+		if err != nil {
+			return fmt.Errorf("failed to revoke subject: %w", err)
+		}
+		return notify(tx, revocationEvent{Subject: subject, Before: record.RevokedBefore})
+	})
+}
+
+// IsRevoked implements middleware.RevocationChecker
+func (s *RevocationStore) IsRevoked(ctx context.Context, tokenID, subject string, issuedAt time.Time) (bool, error) {
+	db := s.db.WithContext(ctx)
+
+	if tokenID != "" {
+		var count int64
+		err := db.Model(&RevokedToken{}).
+			Where("token_id = ? AND expires_at > ?", tokenID, time.Now()).
+			Count(&count).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to check revoked token: %w", err)
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	if subject != "" {
+		var record RevokedSubject
+		err := db.Where("subject = ?", subject).Limit(1).Find(&record).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to check revoked subject: %w", err)
+		}
+		if record.Subject != "" && (issuedAt.IsZero() || issuedAt.Before(record.RevokedBefore)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// PurgeExpired deletes token revocations past their expiry
+func (s *RevocationStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&RevokedToken{})
+	return result.RowsAffected, result.Error
+}
+
+// LoadInto copies all current revocations into sink
+func (s *RevocationStore) LoadInto(ctx context.Context, sink RevocationSink) error {
+	db := s.db.WithContext(ctx)
+
+	var tokens []RevokedToken
+	if err := db.Where("expires_at > ?", time.Now()).Find(&tokens).Error; err != nil {
+		return fmt.Errorf("failed to load revoked tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := sink.RevokeToken(ctx, token.TokenID, token.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	var subjects []RevokedSubject
+	if err := db.Find(&subjects).Error; err != nil {
+		return fmt.Errorf("failed to load revoked subjects: %w", err)
+	}
+	for _, subject := range subjects {
+		if err := sink.RevokeSubject(ctx, subject.Subject, subject.RevokedBefore); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListenForRevocations keeps sink in sync with the store until ctx is
+// cancelled. It loads existing revocations, then applies revocations
+// published by any replica as they happen, reconnecting after failures and
+// reloading so nothing published while disconnected is missed.
+func ListenForRevocations(ctx context.Context, cfg config.DatabaseConfig, store *RevocationStore, sink RevocationSink) error {
+	logger := logging.Default().With(slog.String("component", "revocation_listener"))
+	delay := time.Second
+
+	for {
+		err := listenOnce(ctx, cfg, store, sink, func() { delay = time.Second })
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logger.Warn("revocation listener disconnected", logging.Err(err), slog.Duration("retry_in", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// listenOnce runs a single LISTEN session, calling connected once caught up
+func listenOnce(ctx context.Context, cfg config.DatabaseConfig, store *RevocationStore, sink RevocationSink, connected func()) error {
+	conn, err := pgx.Connect(ctx, cfg.ConnectionString())
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{RevocationChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	// Load after LISTEN so revocations made in between are not missed
+	if err := store.LoadInto(ctx, sink); err != nil {
+		return err
+	}
+	connected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event revocationEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			logging.Default().Warn("invalid revocation notification", logging.Err(err))
+			continue
+		}
+
+		if event.TokenID != "" {
+			err = sink.RevokeToken(ctx, event.TokenID, event.ExpiresAt)
+		} else if event.Subject != "" {
+			err = sink.RevokeSubject(ctx, event.Subject, event.Before)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply revocation: %w", err)
+		}
+	}
+}
+
+// notify publishes a revocation within the transaction, so listeners only
+// see it once committed
+func notify(tx *gorm.DB, event revocationEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec("SELECT pg_notify(?, ?)", RevocationChannel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to publish revocation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// lifecycle/components.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/JorgeSaicoski/microservice-commons/database"
+	"github.com/JorgeSaicoski/microservice-commons/server"
+)
+
+// RegisterServer registers the service HTTP server. It binds its listener
+// on start, so port conflicts fail startup, and drains in-flight requests
+// on stop.
+func (r *Runner) RegisterServer(srv *server.Server, dependsOn ...string) *Runner {
+	return r.Register(Hook{
+		Name:      "http-server",
+		Priority:  PriorityHTTP,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			if err := srv.StartAdmin(); err != nil {
+				return err
+			}
+
+			ln, err := net.Listen("tcp", srv.GetHTTPServer().Addr)
+			if err != nil {
+				if admin := srv.GetAdminServer(); admin != nil {
+					admin.Shutdown(ctx)
+				}
+				return err
+			}
+
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					r.Fail(fmt.Errorf("http server: %w", err))
+				}
+			}()
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	})
+}
+
+// RegisterHTTPServer registers a plain http.Server under the given name
+func (r *Runner) RegisterHTTPServer(name string, srv *http.Server, dependsOn ...string) *Runner {
+	return r.Register(Hook{
+		Name:      name,
+		Priority:  PriorityHTTP,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					r.Fail(fmt.Errorf("%s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	})
+}
+
+// RegisterDatabase registers a database connection manager. The
+// connection is established on start (unless already connected) and
+// closed on stop.
+func (r *Runner) RegisterDatabase(cm *database.ConnectionManager) *Runner {
+	return r.Register(Hook{
+		Name:     "database",
+		Priority: PriorityDatabase,
+		OnStart: func(ctx context.Context) error {
+			if cm.GetConnection() != nil {
+				return nil
+			}
+			_, err := cm.Connect()
+			return err
+		},
+		OnStop: func(ctx context.Context) error {
+			return cm.Close()
+		},
+	})
+}
+
+// RegisterWorker registers a background worker. The worker runs until its
+// context is cancelled on stop; returning a non-cancellation error while
+// running triggers shutdown of the whole application.
+func (r *Runner) RegisterWorker(name string, worker func(ctx context.Context) error, dependsOn ...string) *Runner {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	return r.Register(Hook{
+		Name:      name,
+		Priority:  PriorityWorker,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+
+			go func() {
+				defer close(done)
+				if err := worker(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+					r.Fail(fmt.Errorf("worker %s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
@@ -0,0 +1,264 @@
+// lifecycle/runner.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/server"
+)
+
+// Default priorities for common components. Lower priorities start first
+// and stop last, so the HTTP server drains before workers stop and the
+// database is closed at the very end.
+const (
+	PriorityDatabase = 0
+	PriorityWorker   = 100
+	PriorityHTTP     = 200
+)
+
+// Hook describes how a component is started and stopped
+type Hook struct {
+	Name      string
+	Priority  int           // Lower priorities start first and stop last
+	DependsOn []string      // Hooks that must be started before this one
+	Timeout   time.Duration // Per-hook start/stop timeout (0 = bounded by the overall timeout only)
+	OnStart   func(ctx context.Context) error
+	OnStop    func(ctx context.Context) error
+}
+
+// HookError represents a failure of a single hook
+type HookError struct {
+	Hook  string
+	Phase string
+	Err   error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Hook, e.Phase, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// Runner starts registered hooks in dependency and priority order and
+// stops them in reverse order
+type Runner struct {
+	config  server.GracefulShutdownConfig
+	hooks   []Hook
+	started []Hook
+	mu      sync.Mutex
+	failed  chan error
+}
+
+// NewRunner creates a new application runner
+func NewRunner(config server.GracefulShutdownConfig) *Runner {
+	return &Runner{
+		config: config,
+		hooks:  make([]Hook, 0),
+		failed: make(chan error, 1),
+	}
+}
+
+// Register adds hooks to the runner
+func (r *Runner) Register(hooks ...Hook) *Runner {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hooks...)
+	return r
+}
+
+// Fail reports a fatal runtime error from a running component and
+// triggers shutdown when the runner is blocked in Run
+func (r *Runner) Fail(err error) {
+	select {
+	case r.failed <- err:
+	default:
+		// A failure is already pending, shutdown is underway
+	}
+}
+
+// Start starts all hooks in order. If a hook fails to start, the hooks
+// that were already started are stopped and the combined error is returned.
+func (r *Runner) Start(ctx context.Context) error {
+	r.mu.Lock()
+	ordered, err := orderHooks(r.hooks)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range ordered {
+		logging.Default().Info("starting component", slog.String("component", hook.Name))
+
+		if err := runHook(ctx, hook, "start", hook.OnStart); err != nil {
+			stopCtx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+			defer cancel()
+			return errors.Join(err, r.Stop(stopCtx))
+		}
+
+		r.mu.Lock()
+		r.started = append(r.started, hook)
+		r.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Stop stops all started hooks in reverse order. Every hook is given a
+// chance to stop even if an earlier one failed; all errors are combined.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.started = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		logging.Default().Info("stopping component", slog.String("component", hook.Name))
+
+		if err := runHook(ctx, hook, "stop", hook.OnStop); err != nil {
+			logging.Default().Error("failed to stop component", slog.String("component", hook.Name), logging.Err(err))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run starts all hooks, blocks until a shutdown signal is received or a
+// component reports a failure, then stops everything within the
+// configured shutdown timeout
+func (r *Runner) Run() error {
+	if err := r.Start(context.Background()); err != nil {
+		return err
+	}
+
+	logging.EnableSIGHUPToggle()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var runErr error
+	select {
+	case sig := <-quit:
+		logging.Default().Info("received signal, initiating graceful shutdown", slog.String("signal", sig.String()))
+	case runErr = <-r.failed:
+		logging.Default().Error("component failed, initiating graceful shutdown", logging.Err(runErr))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+	defer cancel()
+
+	if err := r.Stop(ctx); err != nil {
+		return errors.Join(runErr, err)
+	}
+
+	logging.Default().Info("shutdown complete")
+	return runErr
+}
+
+// runHook executes a single hook function bounded by the hook timeout and
+// the parent context, so that a stuck hook cannot block the others
+func runHook(ctx context.Context, hook Hook, phase string, fn func(context.Context) error) error {
+	if fn == nil {
+		return nil
+	}
+
+	if hook.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return &HookError{Hook: hook.Name, Phase: phase, Err: err}
+		}
+		return nil
+	case <-ctx.Done():
+		return &HookError{Hook: hook.Name, Phase: phase, Err: fmt.Errorf("timed out: %w", ctx.Err())}
+	}
+}
+
+// orderHooks sorts hooks so that dependencies start first, breaking ties
+// by priority and then by registration order
+func orderHooks(hooks []Hook) ([]Hook, error) {
+	index := make(map[string]int, len(hooks))
+	for i, hook := range hooks {
+		if hook.Name == "" {
+			return nil, fmt.Errorf("hook %d has no name", i)
+		}
+		if _, exists := index[hook.Name]; exists {
+			return nil, fmt.Errorf("duplicate hook name: %s", hook.Name)
+		}
+		index[hook.Name] = i
+	}
+
+	pending := make([]int, len(hooks))
+	dependents := make([][]int, len(hooks))
+	for i, hook := range hooks {
+		for _, dep := range hook.DependsOn {
+			j, exists := index[dep]
+			if !exists {
+				return nil, fmt.Errorf("hook %s depends on unknown hook %s", hook.Name, dep)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ready := make([]int, 0, len(hooks))
+	for i := range hooks {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ordered := make([]Hook, 0, len(hooks))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(a, b int) bool {
+			pa, pb := hooks[ready[a]].Priority, hooks[ready[b]].Priority
+			if pa != pb {
+				return pa < pb
+			}
+			return ready[a] < ready[b]
+		})
+
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, hooks[next])
+
+		for _, dependent := range dependents[next] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) != len(hooks) {
+		return nil, fmt.Errorf("hook dependencies contain a cycle")
+	}
+
+	return ordered, nil
+}
@@ -0,0 +1,44 @@
+// logging/context.go
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the type for values stored in context.Context by this package
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	requestIDContextKey
+)
+
+// WithLogger returns a copy of ctx carrying the given logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey).(*slog.Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return Default()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
@@ -0,0 +1,151 @@
+// logging/logger.go
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+)
+
+// Log output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+var (
+	// level is shared by every logger created by this package so that it
+	// can be changed at runtime
+	level = new(slog.LevelVar)
+
+	// baseLevel is the configured level that runtime changes toggle back to
+	baseLevel atomic.Int64
+
+	defaultLogger atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	defaultLogger.Store(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})))
+}
+
+// Options holds logger configuration
+type Options struct {
+	Level       slog.Level
+	Format      string
+	Output      io.Writer
+	ServiceName string
+	Version     string
+	Environment string
+}
+
+// OptionsFromConfig builds logger options from the service configuration
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Level:       FromConfigLevel(cfg.GetLogLevel()),
+		Format:      cfg.GetLogFormat(),
+		Output:      os.Stdout,
+		ServiceName: cfg.ServiceName,
+		Version:     cfg.ServiceVersion,
+		Environment: cfg.Environment,
+	}
+}
+
+// New creates a logger with the given options. The level is applied to
+// the shared runtime level.
+func New(opts Options) *slog.Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	SetLevel(opts.Level)
+	baseLevel.Store(int64(opts.Level))
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if opts.Format == FormatJSON {
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(output, handlerOpts)
+	}
+
+	logger := slog.New(handler)
+
+	attrs := make([]any, 0, 6)
+	if opts.ServiceName != "" {
+		attrs = append(attrs, slog.String("service", opts.ServiceName))
+	}
+	if opts.Version != "" {
+		attrs = append(attrs, slog.String("version", opts.Version))
+	}
+	if opts.Environment != "" {
+		attrs = append(attrs, slog.String("environment", opts.Environment))
+	}
+
+	return logger.With(attrs...)
+}
+
+// Init creates a logger from the service configuration and installs it as
+// the package and slog default
+func Init(cfg *config.Config) *slog.Logger {
+	logger := New(OptionsFromConfig(cfg))
+	SetDefault(logger)
+	return logger
+}
+
+// Default returns the default logger
+func Default() *slog.Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault replaces the default logger
+func SetDefault(logger *slog.Logger) {
+	defaultLogger.Store(logger)
+	slog.SetDefault(logger)
+}
+
+// Level returns the current log level
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the log level of all loggers created by this package
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel parses a level name (debug, info, warn, error)
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level: %s", name)
+	}
+}
+
+// FromConfigLevel converts a configuration log level to a slog level
+func FromConfigLevel(l config.LogLevel) slog.Level {
+	parsed, _ := ParseLevel(string(l))
+	return parsed
+}
+
+// Err returns an attribute for an error
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.String("error", "")
+	}
+	return slog.String("error", err.Error())
+}
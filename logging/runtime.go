@@ -0,0 +1,90 @@
+// logging/runtime.go
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var sighupOnce sync.Once
+
+// levelPayload is the request and response body of the level endpoint
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler to inspect and change the log level
+// at runtime. GET returns the current level; PUT or POST with a JSON body
+// {"level":"debug"} or a ?level= query parameter changes it.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			name := r.URL.Query().Get("level")
+			if name == "" {
+				var payload levelPayload
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					writeLevelError(w, http.StatusBadRequest, "invalid request body")
+					return
+				}
+				name = payload.Level
+			}
+
+			newLevel, err := ParseLevel(name)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			SetLevel(newLevel)
+			Default().Info("log level changed", slog.String("level", levelName(newLevel)))
+		default:
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levelPayload{Level: levelName(Level())})
+	})
+}
+
+// EnableSIGHUPToggle toggles the log level between the configured level
+// and debug each time the process receives SIGHUP. Calling it more than
+// once has no additional effect.
+func EnableSIGHUPToggle() {
+	sighupOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+
+		go func() {
+			for range signals {
+				newLevel := slog.LevelDebug
+				if Level() == slog.LevelDebug {
+					newLevel = slog.Level(baseLevel.Load())
+				}
+
+				SetLevel(newLevel)
+				Default().Info("log level changed by SIGHUP", slog.String("level", levelName(newLevel)))
+			}
+		}()
+	})
+}
+
+// levelName returns the lowercase name of a level
+func levelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// writeLevelError writes a JSON error for the level endpoint
+func writeLevelError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,173 @@
+// metrics/histogram.go
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultLatencyBuckets are upper bounds in milliseconds suited to request
+// and query latencies
+var DefaultLatencyBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Histogram counts observations into cumulative buckets. It implements
+// expvar.Var so it can be published on /debug/vars.
+type Histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewHistogram creates an unpublished histogram with the given upper bounds
+func NewHistogram(bounds []float64) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultLatencyBuckets
+	}
+
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+
+	return &Histogram{
+		bounds:  sorted,
+		buckets: make([]uint64, len(sorted)),
+	}
+}
+
+// Observe records a value
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += value
+
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.buckets[i]++
+			break
+		}
+	}
+}
+
+// Snapshot returns the current state of the histogram
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := HistogramSnapshot{
+		Count:   h.count,
+		Sum:     h.sum,
+		Buckets: make(map[string]uint64, len(h.bounds)+1),
+	}
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i]
+		snapshot.Buckets[formatBound(bound)] = cumulative
+	}
+	snapshot.Buckets["+Inf"] = h.count
+
+	return snapshot
+}
+
+// String returns the histogram as JSON, for expvar
+func (h *Histogram) String() string {
+	data, _ := json.Marshal(h.Snapshot())
+	return string(data)
+}
+
+// HistogramSnapshot is a point-in-time view of a histogram. Buckets are
+// cumulative and keyed by their upper bound.
+type HistogramSnapshot struct {
+	Count   uint64            `json:"count"`
+	Sum     float64           `json:"sum"`
+	Buckets map[string]uint64 `json:"buckets"`
+}
+
+// HistogramVec is a set of histograms sharing bounds, keyed by a label
+// such as "select:users"
+type HistogramVec struct {
+	mu         sync.RWMutex
+	bounds     []float64
+	histograms map[string]*Histogram
+}
+
+// NewHistogramVec creates a histogram set published on expvar under name.
+// Calling it again with the same name returns the existing set.
+func NewHistogramVec(name string, bounds []float64) *HistogramVec {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	if existing, ok := expvar.Get(name).(*HistogramVec); ok {
+		return existing
+	}
+
+	vec := &HistogramVec{
+		bounds:     bounds,
+		histograms: make(map[string]*Histogram),
+	}
+	expvar.Publish(name, vec)
+
+	return vec
+}
+
+// WithLabel returns the histogram for label, creating it if needed
+func (v *HistogramVec) WithLabel(label string) *Histogram {
+	v.mu.RLock()
+	histogram, ok := v.histograms[label]
+	v.mu.RUnlock()
+	if ok {
+		return histogram
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if histogram, ok := v.histograms[label]; ok {
+		return histogram
+	}
+	histogram = NewHistogram(v.bounds)
+	v.histograms[label] = histogram
+
+	return histogram
+}
+
+// Observe records a value for label
+func (v *HistogramVec) Observe(label string, value float64) {
+	v.WithLabel(label).Observe(value)
+}
+
+// Snapshot returns the current state of every histogram in the set
+func (v *HistogramVec) Snapshot() map[string]HistogramSnapshot {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	snapshot := make(map[string]HistogramSnapshot, len(v.histograms))
+	for label, histogram := range v.histograms {
+		snapshot[label] = histogram.Snapshot()
+	}
+	return snapshot
+}
+
+// String returns the set as JSON, for expvar
+func (v *HistogramVec) String() string {
+	data, _ := json.Marshal(v.Snapshot())
+	return string(data)
+}
+
+// publishMu serialises lookups and publication of expvar names
+var publishMu sync.Mutex
+
+// formatBound renders a bucket bound as a compact string
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
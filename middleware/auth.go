@@ -1,20 +1,56 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// tokenContextKey is the context.Context key for the caller's bearer token
+type tokenContextKey struct{}
+
+// ContextWithToken returns a copy of ctx carrying the caller's bearer token
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFromContext returns the bearer token of the authenticated caller,
+// if authentication middleware stored one
+func TokenFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	token, ok := ctx.Value(tokenContextKey{}).(string)
+	return token, ok && token != ""
+}
+
 // AuthConfig holds configuration for authentication middleware
 type AuthConfig struct {
 	SkipPaths      []string
 	TokenExtractor func(*gin.Context) (string, error)
 	TokenValidator func(string) (map[string]interface{}, error)
 	ErrorHandler   func(*gin.Context, error)
+
+	// RevocationChecker rejects validated tokens that were revoked (optional)
+	RevocationChecker RevocationChecker
+
+	// AllowUnverified lets requests whose token could not be verified
+	// (ErrUnverifiedToken) through as anonymous, without user context.
+	// Only for routes that tolerate unauthenticated callers.
+	AllowUnverified bool
+
+	// Optional lets requests with a missing, invalid or revoked token
+	// through as anonymous instead of rejecting them
+	Optional bool
 }
 
+// AuthUnverifiedKey is set in the context of requests let through by
+// AllowUnverified
+const AuthUnverifiedKey = "auth_unverified"
+
 // AuthError represents authentication errors
 type AuthError struct {
 	Code    string `json:"code"`
@@ -43,6 +79,12 @@ var (
 		Code:    "insufficient_permissions",
 		Message: "Insufficient permissions for this operation",
 	}
+	// ErrUnverifiedToken is returned by validators that could neither accept
+	// nor reject a token, e.g. during an identity provider outage
+	ErrUnverifiedToken = &AuthError{
+		Code:    "unverified_token",
+		Message: "Authorization token could not be verified",
+	}
 )
 
 // DefaultAuthConfig returns default authentication configuration
@@ -63,10 +105,19 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 			return
 		}
 
+		// Reject, or continue without authentication if optional
+		fail := func(err error) {
+			if config.Optional {
+				c.Next()
+				return
+			}
+			config.ErrorHandler(c, err)
+		}
+
 		// Extract token
 		token, err := config.TokenExtractor(c)
 		if err != nil {
-			config.ErrorHandler(c, err)
+			fail(err)
 			return
 		}
 
@@ -74,16 +125,32 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 		if config.TokenValidator != nil {
 			claims, err := config.TokenValidator(token)
 			if err != nil {
-				config.ErrorHandler(c, err)
+				if config.AllowUnverified && errors.Is(err, ErrUnverifiedToken) {
+					c.Set(AuthUnverifiedKey, true)
+					c.Next()
+					return
+				}
+				fail(err)
 				return
 			}
 
+			if config.RevocationChecker != nil {
+				if err := checkRevocation(c.Request.Context(), config.RevocationChecker, claims); err != nil {
+					fail(err)
+					return
+				}
+			}
+
 			// Store claims in context
 			for key, value := range claims {
 				c.Set(key, value)
 			}
+			attachUserToLogger(c)
 		}
 
+		// Make the caller's token available for propagation to other services
+		c.Request = c.Request.WithContext(ContextWithToken(c.Request.Context(), token))
+
 		c.Next()
 	}
 }
@@ -171,34 +238,14 @@ func RequireAuth(tokenValidator func(string) (map[string]interface{}, error)) gi
 	return NewAuthMiddleware(config)
 }
 
-// OptionalAuth creates an authentication middleware that doesn't fail if no token is provided
+// OptionalAuth creates an authentication middleware that doesn't fail if no
+// valid token is provided. Use NewAuthMiddleware with Optional set to add a
+// RevocationChecker.
 func OptionalAuth(tokenValidator func(string) (map[string]interface{}, error)) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Try to extract token
-		token, err := BearerTokenExtractor(c)
-		if err != nil {
-			// No token provided, continue without authentication
-			c.Next()
-			return
-		}
-
-		// Validate token if provided
-		if tokenValidator != nil {
-			claims, err := tokenValidator(token)
-			if err != nil {
-				// Invalid token, but don't fail the request
-				c.Next()
-				return
-			}
-
-			// Store claims in context
-			for key, value := range claims {
-				c.Set(key, value)
-			}
-		}
-
-		c.Next()
-	}
+	config := DefaultAuthConfig()
+	config.TokenValidator = tokenValidator
+	config.Optional = true
+	return NewAuthMiddleware(config)
 }
 
 // APIKeyAuth creates an API key authentication middleware
@@ -217,6 +264,7 @@ func APIKeyAuth(validAPIKeys map[string]string) gin.HandlerFunc {
 		if userID, exists := validAPIKeys[apiKey]; exists {
 			c.Set("user_id", userID)
 			c.Set("auth_method", "api_key")
+			attachUserToLogger(c)
 			c.Next()
 		} else {
 			DefaultAuthErrorHandler(c, &AuthError{
@@ -0,0 +1,363 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultBodyLogMaxBytes is the default maximum body size captured
+const DefaultBodyLogMaxBytes = 4096
+
+// BodyLogConfig holds configuration for capturing request and response
+// bodies in access logs
+type BodyLogConfig struct {
+	Enabled bool
+
+	// MaxBytes bounds each captured body. Larger bodies are replaced by a
+	// size marker rather than logged partially, since a truncated body
+	// cannot be reliably redacted.
+	MaxBytes int
+
+	// ContentTypes lists the media types that are captured. Types ending
+	// in +json are treated as JSON.
+	ContentTypes []string
+
+	// RedactFields are matched case-insensitively against JSON keys and
+	// form field names; any key containing one of them is masked
+	RedactFields []string
+
+	// RedactHeaders are masked when LogHeaders is set
+	RedactHeaders []string
+	LogHeaders    bool
+
+	// RedactCreditCards masks values that pass utils.IsValidCreditCard
+	RedactCreditCards bool
+
+	// IncludeRoutes restricts capture to these paths or route templates;
+	// ExcludeRoutes disables capture for them
+	IncludeRoutes []string
+	ExcludeRoutes []string
+}
+
+// DefaultBodyLogConfig returns body capture configuration with sensible
+// redaction defaults. Capture is disabled until Enabled is set.
+func DefaultBodyLogConfig() BodyLogConfig {
+	return BodyLogConfig{
+		Enabled:  false,
+		MaxBytes: DefaultBodyLogMaxBytes,
+		ContentTypes: []string{
+			"application/json",
+			"application/x-www-form-urlencoded",
+		},
+		RedactFields: []string{
+			"password", "passwd", "secret", "token", "authorization",
+			"api_key", "apikey", "credit_card", "card_number", "cvv",
+		},
+		RedactHeaders: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key", "X-CSRF-Token",
+		},
+		RedactCreditCards: true,
+	}
+}
+
+// BodyLogConfigFromConfig returns the default body capture configuration,
+// enabled according to LOG_BODIES (off by default in production)
+func BodyLogConfigFromConfig(cfg *config.Config) BodyLogConfig {
+	bodyConfig := DefaultBodyLogConfig()
+	bodyConfig.Enabled = cfg.ShouldLogBodies()
+	return bodyConfig
+}
+
+// bodyCapture holds the captured bodies and headers of a single request
+type bodyCapture struct {
+	config          BodyLogConfig
+	requestBody     []byte
+	requestTooLarge bool
+	response        *bodyCaptureWriter
+}
+
+// captureBodies starts capturing the request and response of c, or returns
+// nil if capture does not apply to this route
+func (bc BodyLogConfig) captureBodies(c *gin.Context, route string) *bodyCapture {
+	if !bc.Enabled || !bc.routeEnabled(c.Request.URL.Path, route) {
+		return nil
+	}
+
+	capture := &bodyCapture{config: bc}
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody && bc.capturable(c.Request.Header.Get("Content-Type")) {
+		limit := bc.maxBytes()
+		buf, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+		if err == nil {
+			capture.requestTooLarge = len(buf) > limit
+			if !capture.requestTooLarge {
+				capture.requestBody = buf
+			}
+		}
+
+		// Hand the consumed bytes back to the handler
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), c.Request.Body), c.Request.Body}
+	}
+
+	capture.response = &bodyCaptureWriter{ResponseWriter: c.Writer, limit: bc.maxBytes()}
+	c.Writer = capture.response
+
+	return capture
+}
+
+// entryFields fills the body and header fields of an access log entry
+func (capture *bodyCapture) entryFields(c *gin.Context, entry *AccessLogEntry) {
+	bc := capture.config
+
+	if capture.requestTooLarge {
+		entry.RequestBody = bodyTooLarge(bc.maxBytes())
+	} else if len(capture.requestBody) > 0 {
+		entry.RequestBody = bc.redactBody(c.Request.Header.Get("Content-Type"), capture.requestBody)
+	}
+
+	contentType := capture.response.Header().Get("Content-Type")
+	if bc.capturable(contentType) {
+		if capture.response.overflow {
+			entry.ResponseBody = bodyTooLarge(bc.maxBytes())
+		} else if capture.response.body.Len() > 0 {
+			entry.ResponseBody = bc.redactBody(contentType, capture.response.body.Bytes())
+		}
+	}
+
+	if bc.LogHeaders {
+		entry.RequestHeaders = bc.redactHeaders(c.Request.Header)
+		entry.ResponseHeaders = bc.redactHeaders(capture.response.Header())
+	}
+}
+
+// routeEnabled reports whether capture applies to the path or route template
+func (bc BodyLogConfig) routeEnabled(path, route string) bool {
+	matches := func(routes []string) bool {
+		return utils.Contains(routes, path) || (route != "" && utils.Contains(routes, route))
+	}
+
+	if matches(bc.ExcludeRoutes) {
+		return false
+	}
+	if len(bc.IncludeRoutes) > 0 {
+		return matches(bc.IncludeRoutes)
+	}
+	return true
+}
+
+// capturable reports whether the content type is one that is captured
+func (bc BodyLogConfig) capturable(contentType string) bool {
+	mediaType := parseMediaType(contentType)
+	if mediaType == "" {
+		return false
+	}
+
+	for _, allowed := range bc.ContentTypes {
+		if mediaType == strings.ToLower(allowed) {
+			return true
+		}
+		if allowed == "application/json" && strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBytes returns the capture limit, falling back to the default
+func (bc BodyLogConfig) maxBytes() int {
+	if bc.MaxBytes <= 0 {
+		return DefaultBodyLogMaxBytes
+	}
+	return bc.MaxBytes
+}
+
+// redactBody masks sensitive fields in a captured body
+func (bc BodyLogConfig) redactBody(contentType string, body []byte) string {
+	mediaType := parseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return "[unparseable JSON body omitted]"
+		}
+
+		redacted, err := json.Marshal(bc.redactJSON(value))
+		if err != nil {
+			return "[unparseable JSON body omitted]"
+		}
+		return string(redacted)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[unparseable form body omitted]"
+		}
+
+		for key, fieldValues := range values {
+			for i, value := range fieldValues {
+				if bc.sensitiveField(key) || bc.sensitiveValue(value) {
+					fieldValues[i] = config.RedactedValue
+				}
+			}
+		}
+		return values.Encode()
+
+	default:
+		words := strings.Fields(string(body))
+		for i, word := range words {
+			if bc.sensitiveValue(word) {
+				words[i] = config.RedactedValue
+			}
+		}
+		return strings.Join(words, " ")
+	}
+}
+
+// redactQuery masks sensitive parameters in a raw query string, keeping
+// the others as sent
+func (bc BodyLogConfig) redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			key = name
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		if bc.sensitiveField(key) || bc.sensitiveValue(value) {
+			params[i] = url.QueryEscape(key) + "=" + config.RedactedValue
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// redactJSON walks a decoded JSON value masking sensitive fields
+func (bc BodyLogConfig) redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if bc.sensitiveField(key) {
+				v[key] = config.RedactedValue
+			} else {
+				v[key] = bc.redactJSON(child)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = bc.redactJSON(child)
+		}
+		return v
+	case string:
+		if bc.sensitiveValue(v) {
+			return config.RedactedValue
+		}
+		return v
+	case json.Number:
+		if bc.sensitiveValue(v.String()) {
+			return config.RedactedValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// redactHeaders flattens headers for logging, masking sensitive ones
+func (bc BodyLogConfig) redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if utils.ContainsIgnoreCase(bc.RedactHeaders, name) {
+			headers[name] = config.RedactedValue
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// sensitiveField reports whether a field name matches a redacted field
+func (bc BodyLogConfig) sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range bc.RedactFields {
+		if strings.Contains(name, strings.ToLower(field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveValue reports whether a value looks like a credit card number
+func (bc BodyLogConfig) sensitiveValue(value string) bool {
+	return bc.RedactCreditCards && utils.IsValidCreditCard(value)
+}
+
+// parseMediaType returns the lowercased media type of a Content-Type header
+func parseMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(mediaType)
+}
+
+// bodyTooLarge returns the marker logged in place of oversized bodies
+func bodyTooLarge(limit int) string {
+	return fmt.Sprintf("[body larger than %d bytes omitted]", limit)
+}
+
+// bodyCaptureWriter copies up to limit bytes of the response body
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCaptureWriter) capture(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// LoggerKey is the context key for the request-scoped logger
+	LoggerKey = "logger"
+	// loggerUserKey records the user ID already attached to the logger
+	loggerUserKey = "logger_user_id"
+)
+
+// ContextLoggerMiddleware attaches a child logger carrying the request ID,
+// trace and span IDs, method, route and client IP to the Gin context and to the request
+// context.Context, so handler and database logs are correlated. It should
+// run after the request ID middleware.
+func ContextLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		requestID := MustGetRequestID(c)
+		if requestID != "" {
+			attrs = append([]any{slog.String("request_id", requestID)}, attrs...)
+		}
+
+		if sc := tracing.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			attrs = append(attrs,
+				slog.String("trace_id", sc.TraceID.String()),
+				slog.String("span_id", sc.SpanID.String()),
+			)
+		}
+
+		logger := logging.FromContext(c.Request.Context()).With(attrs...)
+
+		ctx := logging.WithLogger(c.Request.Context(), logger)
+		if requestID != "" {
+			ctx = logging.WithRequestID(ctx, requestID)
+		}
+
+		c.Set(LoggerKey, logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		// Pick up a user set by authentication that ran earlier
+		attachUserToLogger(c)
+
+		c.Next()
+	}
+}
+
+// Logger returns the request-scoped logger, or the default logger if the
+// context logger middleware is not installed
+func Logger(c *gin.Context) *slog.Logger {
+	if value, exists := c.Get(LoggerKey); exists {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return logging.Default()
+}
+
+// attachUserToLogger adds the authenticated user ID to the request-scoped
+// logger once it is known
+func attachUserToLogger(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok || userID == "" {
+		return
+	}
+
+	if _, exists := c.Get(LoggerKey); !exists {
+		return
+	}
+
+	if attached, exists := c.Get(loggerUserKey); exists && attached == userID {
+		return
+	}
+
+	logger := Logger(c).With(slog.String("user_id", userID))
+	c.Set(LoggerKey, logger)
+	c.Set(loggerUserKey, userID)
+	c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+}
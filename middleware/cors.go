@@ -1,52 +1,107 @@
 package middleware
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/JorgeSaicoski/microservice-commons/config"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 // CORSConfig holds CORS configuration options
 type CORSConfig struct {
-	AllowedOrigins   []string
+	AllowedOrigins   []string // Exact origins, "*", or wildcard subdomains such as https://*.example.com
 	AllowedMethods   []string
 	AllowedHeaders   []string
 	ExposedHeaders   []string
 	AllowCredentials bool
 	MaxAge           time.Duration
+
+	// PathOverrides replaces the policy for requests under a path prefix,
+	// such as "/public/" or "/webhooks/". The longest matching prefix wins.
+	PathOverrides map[string]CORSConfig
 }
 
 // DefaultCORSConfig returns a CORS configuration with sensible defaults
 func DefaultCORSConfig() CORSConfig {
+	return corsConfigFrom([]string{"http://localhost:3000"}, config.DefaultCORSConfig())
+}
+
+// CORSConfigFromConfig returns the CORS configuration loaded from
+// ALLOWED_ORIGINS and the CORS_* environment variables. Unset values use
+// the defaults.
+func CORSConfigFromConfig(cfg *config.Config) CORSConfig {
+	return corsConfigFrom(cfg.AllowedOrigins, cfg.CORSConfig.WithDefaults())
+}
+
+func corsConfigFrom(origins []string, cc config.CORSConfig) CORSConfig {
 	return CORSConfig{
-		AllowedOrigins: []string{"http://localhost:3000"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowedHeaders: []string{
-			"Origin",
-			"Content-Length",
-			"Content-Type",
-			"Authorization",
-			"X-Requested-With",
-			"Accept",
-			"Accept-Encoding",
-			"Accept-Language",
-			"Cache-Control",
-		},
-		ExposedHeaders: []string{
-			"Content-Length",
-			"Content-Type",
-			"X-Request-ID",
-		},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowedOrigins:   append([]string(nil), origins...),
+		AllowedMethods:   append([]string(nil), cc.AllowedMethods...),
+		AllowedHeaders:   append([]string(nil), cc.AllowedHeaders...),
+		ExposedHeaders:   append([]string(nil), cc.ExposedHeaders...),
+		AllowCredentials: cc.AllowsCredentials(),
+		MaxAge:           cc.MaxAge,
 	}
 }
 
-// NewCORSMiddleware creates a new CORS middleware with the given configuration
+// Validate checks the origins, including those of path overrides
+func (c CORSConfig) Validate() error {
+	if err := config.ValidateOrigins(c.AllowedOrigins, c.AllowCredentials); err != nil {
+		return err
+	}
+	for prefix, override := range c.PathOverrides {
+		if err := override.Validate(); err != nil {
+			return fmt.Errorf("CORS override for %s: %w", prefix, err)
+		}
+	}
+	return nil
+}
+
+// NewCORSMiddleware creates a new CORS middleware with the given
+// configuration. It panics if the configuration is invalid.
 func NewCORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	if err := config.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid CORS configuration: %v", err))
+	}
+
+	handler := newCORSHandler(config)
+	if len(config.PathOverrides) == 0 {
+		return handler
+	}
+
+	type override struct {
+		prefix  string
+		handler gin.HandlerFunc
+	}
+	overrides := make([]override, 0, len(config.PathOverrides))
+	for prefix, overrideConfig := range config.PathOverrides {
+		overrides = append(overrides, override{prefix: prefix, handler: newCORSHandler(overrideConfig)})
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return len(overrides[i].prefix) > len(overrides[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		for _, override := range overrides {
+			if strings.HasPrefix(c.Request.URL.Path, override.prefix) {
+				override.handler(c)
+				return
+			}
+		}
+		handler(c)
+	}
+}
+
+// newCORSHandler creates the gin-contrib CORS handler for one policy.
+// Wildcard origins are matched here rather than by gin-contrib/cors so that
+// https://*.example.com only matches subdomains of example.com.
+func newCORSHandler(config CORSConfig) gin.HandlerFunc {
 	corsConfig := cors.Config{
-		AllowOrigins:     config.AllowedOrigins,
 		AllowMethods:     config.AllowedMethods,
 		AllowHeaders:     config.AllowedHeaders,
 		ExposeHeaders:    config.ExposedHeaders,
@@ -54,9 +109,65 @@ func NewCORSMiddleware(config CORSConfig) gin.HandlerFunc {
 		MaxAge:           config.MaxAge,
 	}
 
+	var patterns []originPattern
+	for _, origin := range config.AllowedOrigins {
+		switch {
+		case origin == "*":
+			corsConfig.AllowAllOrigins = true
+		case strings.Contains(origin, "*"):
+			patterns = append(patterns, newOriginPattern(origin))
+		default:
+			corsConfig.AllowOrigins = append(corsConfig.AllowOrigins, origin)
+		}
+	}
+
+	if corsConfig.AllowAllOrigins {
+		corsConfig.AllowOrigins = nil
+	} else if len(patterns) > 0 || len(corsConfig.AllowOrigins) == 0 {
+		// With no origins at all, every cross-origin request is refused
+		corsConfig.AllowOriginFunc = func(origin string) bool {
+			for _, pattern := range patterns {
+				if pattern.matches(origin) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
 	return cors.New(corsConfig)
 }
 
+// originPattern matches origins on any subdomain of a domain
+type originPattern struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
+// newOriginPattern parses a validated pattern such as https://*.example.com
+func newOriginPattern(origin string) originPattern {
+	parsed, _ := url.Parse(origin)
+	return originPattern{
+		scheme: parsed.Scheme,
+		suffix: strings.ToLower(strings.TrimPrefix(parsed.Hostname(), "*")),
+		port:   parsed.Port(),
+	}
+}
+
+func (p originPattern) matches(origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Path != "" {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	return parsed.Scheme == p.scheme &&
+		parsed.Port() == p.port &&
+		len(host) > len(p.suffix) &&
+		strings.HasSuffix(host, p.suffix)
+}
+
 // DefaultCORSMiddleware creates a CORS middleware with default configuration
 func DefaultCORSMiddleware() gin.HandlerFunc {
 	return NewCORSMiddleware(DefaultCORSConfig())
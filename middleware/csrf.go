@@ -0,0 +1,348 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// CSRF token names
+const (
+	CSRFTokenKey   = "csrf_token"   // Context key for the request's token
+	CSRFHeader     = "X-CSRF-Token" // Header carrying the token
+	CSRFFormField  = "csrf_token"   // Form field carrying the token
+	csrfTokenBytes = 32
+)
+
+// CSRFMode selects how tokens are issued and checked
+type CSRFMode string
+
+const (
+	// CSRFDoubleSubmit stores the token in a cookie and expects it echoed in
+	// a header or form field. It needs no server-side state.
+	CSRFDoubleSubmit CSRFMode = "double_submit"
+	// CSRFSynchronizer stores the token per session in a CSRFTokenStore
+	CSRFSynchronizer CSRFMode = "synchronizer"
+)
+
+// CSRFConfig holds configuration for CSRF middleware
+type CSRFConfig struct {
+	Mode CSRFMode
+
+	// Origins trusted to send state-changing requests besides the
+	// service's own, such as https://app.example.com or
+	// https://*.example.com. "*" is ignored.
+	AllowedOrigins []string
+
+	HeaderName string
+	FormField  string
+	TokenTTL   time.Duration
+
+	// Double-submit cookie. It is readable by scripts so SPAs can echo it.
+	CookieName     string
+	CookiePath     string
+	CookieDomain   string
+	CookieSecure   bool
+	CookieSameSite http.SameSite
+
+	// Synchronizer tokens. SessionID returns the caller's session, or "" if
+	// it has none; requests without a session only get the origin check.
+	SessionID func(*gin.Context) string
+	Store     CSRFTokenStore // nil uses an in-memory store
+
+	// Requests authenticated by headers browsers never attach on their own
+	// cannot be forged and are exempt
+	ExemptBearerTokens bool
+	APIKeyHeader       string // Empty disables the API key exemption
+
+	SkipPaths []string
+}
+
+// DefaultCSRFConfig returns a default double-submit CSRF configuration
+func DefaultCSRFConfig() CSRFConfig {
+	return CSRFConfig{
+		Mode:               CSRFDoubleSubmit,
+		HeaderName:         CSRFHeader,
+		FormField:          CSRFFormField,
+		TokenTTL:           12 * time.Hour,
+		CookieName:         "csrf_token",
+		CookiePath:         "/",
+		CookieSecure:       true,
+		CookieSameSite:     http.SameSiteLaxMode,
+		ExemptBearerTokens: true,
+		APIKeyHeader:       "X-API-Key",
+		SkipPaths:          []string{"/health", "/ready", "/metrics"},
+	}
+}
+
+// CSRFConfigFromConfig returns the default CSRF configuration trusting
+// ALLOWED_ORIGINS, with secure cookies in production
+func CSRFConfigFromConfig(cfg *config.Config) CSRFConfig {
+	csrfConfig := DefaultCSRFConfig()
+	csrfConfig.AllowedOrigins = append([]string(nil), cfg.AllowedOrigins...)
+	csrfConfig.CookieSecure = cfg.IsProduction()
+	return csrfConfig
+}
+
+// Validate checks the mode and allowed origins
+func (c CSRFConfig) Validate() error {
+	switch c.Mode {
+	case "", CSRFDoubleSubmit:
+	case CSRFSynchronizer:
+		if c.SessionID == nil {
+			return fmt.Errorf("synchronizer mode requires SessionID")
+		}
+	default:
+		return fmt.Errorf("unknown CSRF mode %q", c.Mode)
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if err := config.ValidateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csrfProtector checks one CSRF policy
+type csrfProtector struct {
+	config   CSRFConfig
+	origins  map[string]bool
+	patterns []originPattern
+}
+
+// NewCSRFMiddleware creates a middleware that protects state-changing
+// requests against cross-site request forgery. Their Origin, or Referer
+// when Origin is absent, must be the service itself or an allowed origin,
+// and they must carry the token issued on earlier requests; see
+// GetCSRFToken. It panics if the configuration is invalid.
+func NewCSRFMiddleware(config CSRFConfig) gin.HandlerFunc {
+	defaults := DefaultCSRFConfig()
+	if config.Mode == "" {
+		config.Mode = defaults.Mode
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = defaults.HeaderName
+	}
+	if config.FormField == "" {
+		config.FormField = defaults.FormField
+	}
+	if config.TokenTTL == 0 {
+		config.TokenTTL = defaults.TokenTTL
+	}
+	if config.CookieName == "" {
+		config.CookieName = defaults.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = defaults.CookiePath
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = defaults.CookieSameSite
+	}
+
+	if err := config.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid CSRF configuration: %v", err))
+	}
+	if config.Mode == CSRFSynchronizer && config.Store == nil {
+		config.Store = NewMemoryCSRFTokenStore()
+	}
+
+	protector := &csrfProtector{config: config, origins: make(map[string]bool)}
+	for _, origin := range config.AllowedOrigins {
+		switch {
+		case origin == "*":
+		case strings.Contains(origin, "*"):
+			protector.patterns = append(protector.patterns, newOriginPattern(origin))
+		default:
+			protector.origins[strings.ToLower(origin)] = true
+		}
+	}
+
+	return protector.handle
+}
+
+// CSRFMiddleware creates a double-submit CSRF middleware trusting the
+// given origins
+func CSRFMiddleware(allowedOrigins ...string) gin.HandlerFunc {
+	config := DefaultCSRFConfig()
+	config.AllowedOrigins = allowedOrigins
+	return NewCSRFMiddleware(config)
+}
+
+// GetCSRFToken returns the request's CSRF token, to embed in forms
+// (as CSRFFormField) or hand to SPAs (as CSRFHeader). It is empty for
+// exempt and skipped requests, and for synchronizer requests without a
+// session.
+func GetCSRFToken(c *gin.Context) string {
+	return c.GetString(CSRFTokenKey)
+}
+
+// CSRFTokenHandler responds with the request's CSRF token, for SPAs to
+// fetch on startup, e.g. router.GET("/csrf", middleware.CSRFTokenHandler)
+func CSRFTokenHandler(c *gin.Context) {
+	responses.Data(c, gin.H{"csrf_token": GetCSRFToken(c)})
+}
+
+func (p *csrfProtector) handle(c *gin.Context) {
+	if shouldSkipAuth(c.Request.URL.Path, p.config.SkipPaths) || p.isExempt(c) {
+		c.Next()
+		return
+	}
+
+	safe := isSafeMethod(c.Request.Method)
+	if !safe && !p.originAllowed(c) {
+		Logger(c).Warn("cross-origin request rejected",
+			slog.String("origin", c.GetHeader("Origin")),
+			slog.String("referer", c.GetHeader("Referer")),
+		)
+		responses.Forbidden(c, "Cross-origin request rejected")
+		c.Abort()
+		return
+	}
+
+	var token string
+	var err error
+	switch p.config.Mode {
+	case CSRFSynchronizer:
+		sessionID := p.config.SessionID(c)
+		if sessionID == "" {
+			c.Next()
+			return
+		}
+		token, err = p.config.Store.Get(c.Request.Context(), sessionID)
+		if err == nil && token == "" && safe {
+			token = generateCSRFToken()
+			err = p.config.Store.Save(c.Request.Context(), sessionID, token, p.config.TokenTTL)
+		}
+	default:
+		token = p.cookieToken(c)
+		if token == "" && safe {
+			token = generateCSRFToken()
+			p.setCookie(c, token)
+		}
+	}
+
+	if err != nil {
+		Logger(c).Warn("CSRF token store failed", logging.Err(err))
+		if !safe {
+			responses.ServiceUnavailable(c, "Unable to verify CSRF token")
+			c.Abort()
+			return
+		}
+		token = ""
+	}
+
+	if !safe {
+		provided := c.GetHeader(p.config.HeaderName)
+		if provided == "" && isFormContentType(c.ContentType()) {
+			provided = c.PostForm(p.config.FormField)
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			Logger(c).Warn("CSRF token missing or invalid")
+			responses.Forbidden(c, "CSRF token missing or invalid")
+			c.Abort()
+			return
+		}
+	}
+
+	c.Set(CSRFTokenKey, token)
+	c.Next()
+}
+
+// isExempt reports whether the request is authenticated by a bearer token
+// or API key
+func (p *csrfProtector) isExempt(c *gin.Context) bool {
+	if p.config.ExemptBearerTokens && strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		return true
+	}
+	return p.config.APIKeyHeader != "" && c.GetHeader(p.config.APIKeyHeader) != ""
+}
+
+// originAllowed checks Origin, or Referer when Origin is absent, against
+// the service's own origin and the allowed origins. Requests with neither
+// rely on the token alone.
+func (p *csrfProtector) originAllowed(c *gin.Context) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		referer := c.GetHeader("Referer")
+		if referer == "" {
+			return true
+		}
+		parsed, err := url.Parse(referer)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return false
+		}
+		origin = parsed.Scheme + "://" + parsed.Host
+	}
+
+	origin = strings.ToLower(origin)
+	if origin == strings.ToLower(responses.AbsoluteURL(c, "")) || p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// cookieToken returns the double-submit cookie's token if well formed
+func (p *csrfProtector) cookieToken(c *gin.Context) string {
+	token, err := c.Cookie(p.config.CookieName)
+	if err != nil {
+		return ""
+	}
+	if decoded, err := base64.RawURLEncoding.DecodeString(token); err != nil || len(decoded) != csrfTokenBytes {
+		return ""
+	}
+	return token
+}
+
+func (p *csrfProtector) setCookie(c *gin.Context, token string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     p.config.CookieName,
+		Value:    token,
+		Path:     p.config.CookiePath,
+		Domain:   p.config.CookieDomain,
+		MaxAge:   int(p.config.TokenTTL.Seconds()),
+		Secure:   p.config.CookieSecure,
+		HttpOnly: false,
+		SameSite: p.config.CookieSameSite,
+	})
+}
+
+// generateCSRFToken returns a random URL-safe token
+func generateCSRFToken() string {
+	buf := make([]byte, csrfTokenBytes)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// isSafeMethod reports whether method must not change state (RFC 9110)
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isFormContentType reports whether the body can carry the form field
+func isFormContentType(contentType string) bool {
+	return contentType == "application/x-www-form-urlencoded" || contentType == "multipart/form-data"
+}
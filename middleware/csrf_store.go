@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CSRFTokenStore holds synchronizer tokens per session. Implementations
+// backed by a shared store let replicas validate each other's tokens.
+type CSRFTokenStore interface {
+	// Get returns the session's token, or "" if it has none
+	Get(ctx context.Context, sessionID string) (string, error)
+	// Save stores the session's token for ttl
+	Save(ctx context.Context, sessionID, token string, ttl time.Duration) error
+	// Delete drops the session's token, e.g. on logout
+	Delete(ctx context.Context, sessionID string) error
+}
+
+// memoryCSRFEntry is a session's token and when it expires
+type memoryCSRFEntry struct {
+	token     string
+	expiresAt time.Time
+}
+
+// MemoryCSRFTokenStore keeps synchronizer tokens in process. Tokens are
+// only recognised by the replica that issued them.
+type MemoryCSRFTokenStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCSRFEntry
+	lastSweep time.Time
+}
+
+// NewMemoryCSRFTokenStore creates an in-memory CSRF token store
+func NewMemoryCSRFTokenStore() *MemoryCSRFTokenStore {
+	return &MemoryCSRFTokenStore{
+		entries:   make(map[string]memoryCSRFEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get implements CSRFTokenStore
+func (s *MemoryCSRFTokenStore) Get(ctx context.Context, sessionID string) (string, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	if entry, exists := s.entries[sessionID]; exists && now.Before(entry.expiresAt) {
+		return entry.token, nil
+	}
+	return "", nil
+}
+
+// Save implements CSRFTokenStore
+func (s *MemoryCSRFTokenStore) Save(ctx context.Context, sessionID, token string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[sessionID] = memoryCSRFEntry{token: token, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete implements CSRFTokenStore
+func (s *MemoryCSRFTokenStore) Delete(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, sessionID)
+	return nil
+}
+
+// Len returns the number of sessions holding a token
+func (s *MemoryCSRFTokenStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// sweepLocked drops expired tokens at most once a minute. Callers hold mu.
+func (s *MemoryCSRFTokenStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for sessionID, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, sessionID)
+		}
+	}
+}
@@ -192,6 +192,13 @@ func determineOverallStatus(checks map[string]HealthCheck) HealthStatus {
 	return HealthStatusHealthy
 }
 
+// RunHealthChecks runs the registered checkers and returns the overall
+// status with each check's result
+func (c *HealthConfig) RunHealthChecks() (HealthStatus, map[string]HealthCheck) {
+	checks := runHealthChecks(c.Checkers)
+	return determineOverallStatus(checks), checks
+}
+
 // AddHealthChecker adds a health checker to the configuration
 func (c *HealthConfig) AddHealthChecker(name string, checker HealthChecker) {
 	if c.Checkers == nil {
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses replayed from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyConfig holds configuration for idempotency middleware
+type IdempotencyConfig struct {
+	Store   IdempotencyStore // Default: a new in-memory store
+	Methods []string         // Methods keys are honoured for (default: POST, PATCH)
+
+	TTL         time.Duration // How long responses are replayed (default: 24h)
+	LockTimeout time.Duration // How long an unfinished request holds its key (default: 1m)
+
+	Required        bool  // Reject requests without a key with 400
+	MaxRequestBytes int64 // Larger request bodies are rejected (default: 1MB)
+	MaxBodyBytes    int   // Larger responses are not stored (default: 1MB)
+	SkipPaths       []string
+}
+
+// DefaultIdempotencyConfig returns default idempotency configuration
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Methods:         []string{http.MethodPost, http.MethodPatch},
+		TTL:             24 * time.Hour,
+		LockTimeout:     time.Minute,
+		MaxRequestBytes: 1 << 20,
+		MaxBodyBytes:    1 << 20,
+	}
+}
+
+// NewIdempotencyMiddleware creates a middleware that makes retries of
+// mutating requests safe. The first response for an Idempotency-Key is
+// stored and replayed for retries with the same key from the same user to
+// the same path and query. Retries while the first request is still
+// running get 409; reusing a key with a different body gets 422. Server
+// errors are not stored, so the request can be retried.
+func NewIdempotencyMiddleware(config IdempotencyConfig) gin.HandlerFunc {
+	defaults := DefaultIdempotencyConfig()
+	if config.Store == nil {
+		config.Store = NewMemoryIdempotencyStore()
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = defaults.Methods
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = defaults.LockTimeout
+	}
+	if config.MaxRequestBytes <= 0 {
+		config.MaxRequestBytes = defaults.MaxRequestBytes
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+
+	methods := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[method] = true
+	}
+
+	return func(c *gin.Context) {
+		if !methods[c.Request.Method] || shouldSkipAuth(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			if config.Required {
+				responses.BadRequest(c, "Idempotency-Key header is required")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			responses.BadRequest(c, "Idempotency-Key header is too long")
+			c.Abort()
+			return
+		}
+
+		requestHash, err := hashRequestBody(c, config.MaxRequestBytes)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				responses.Error(c, http.StatusRequestEntityTooLarge, responses.ErrCodeBadRequest, "Request body is too large")
+			} else {
+				responses.BadRequest(c, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+
+		storeKey := idempotencyStoreKey(c, key)
+
+		record, created, err := config.Store.Begin(c.Request.Context(), storeKey, requestHash, config.LockTimeout)
+		if err != nil {
+			// Fail open: an unavailable store should not take the service down
+			Logger(c).Warn("idempotency store failed", logging.Err(err))
+			c.Next()
+			return
+		}
+
+		if !created {
+			switch {
+			case record.RequestHash != requestHash:
+				responses.UnprocessableEntity(c, "Idempotency-Key was already used with a different request")
+			case !record.Completed:
+				responses.Conflict(c, "A request with this Idempotency-Key is still in progress")
+			default:
+				replayIdempotentResponse(c, record.Response)
+			}
+			c.Abort()
+			return
+		}
+
+		capture := &bodyCaptureWriter{ResponseWriter: c.Writer, limit: config.MaxBodyBytes}
+		c.Writer = capture
+
+		// Finish even if the client has gone away
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		defer func() {
+			// Free the key if the handler panicked or failed, so it can be retried
+			if !completed {
+				if err := config.Store.Release(ctx, storeKey); err != nil {
+					Logger(c).Warn("failed to release idempotency key", logging.Err(err))
+				}
+			}
+		}()
+
+		c.Next()
+		c.Writer = capture.ResponseWriter
+
+		status := capture.Status()
+		if status >= http.StatusInternalServerError || capture.overflow {
+			return
+		}
+
+		response := IdempotencyResponse{
+			Status: status,
+			Header: capture.Header().Clone(),
+			Body:   capture.body.Bytes(),
+		}
+		if err := config.Store.Complete(ctx, storeKey, response, config.TTL); err != nil {
+			Logger(c).Warn("failed to store idempotent response", logging.Err(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// IdempotencyMiddleware honours Idempotency-Key on POST and PATCH requests
+// using store
+func IdempotencyMiddleware(store IdempotencyStore) gin.HandlerFunc {
+	config := DefaultIdempotencyConfig()
+	config.Store = store
+	return NewIdempotencyMiddleware(config)
+}
+
+// idempotencyStoreKey scopes the key to the user and target resource, so
+// clients cannot collide with or replay each other's responses, nor replay
+// one resource's response for another on the same route
+func idempotencyStoreKey(c *gin.Context, key string) string {
+	userID, _ := GetUserID(c)
+
+	target := c.Request.URL.Path
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+
+	sum := sha256.Sum256([]byte(userID + "\x00" + c.Request.Method + " " + target + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashRequestBody hashes the request body and puts it back for the handler
+func hashRequestBody(c *gin.Context, limit int64) (string, error) {
+	body, err := readRequestBody(c, limit)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayIdempotentResponse writes a stored response. Headers already set
+// for this request, such as its request ID, are kept.
+func replayIdempotentResponse(c *gin.Context, response IdempotencyResponse) {
+	header := c.Writer.Header()
+	for key, values := range response.Header {
+		if _, exists := header[key]; !exists {
+			header[key] = values
+		}
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+
+	c.Status(response.Status)
+	_, _ = c.Writer.Write(response.Body)
+}
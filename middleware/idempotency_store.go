@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyResponse is a stored response
+type IdempotencyResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyRecord is the state of an idempotency key
+type IdempotencyRecord struct {
+	RequestHash string
+	Completed   bool                // False while the first request is running
+	Response    IdempotencyResponse // Set once Completed
+}
+
+// IdempotencyStore holds idempotency keys. Implementations backed by a
+// shared store let replicas recognise each other's retries.
+type IdempotencyStore interface {
+	// Begin claims key for a request with requestHash until lockTimeout
+	// passes. If the key is already held, its record is returned and
+	// created is false.
+	Begin(ctx context.Context, key, requestHash string, lockTimeout time.Duration) (record *IdempotencyRecord, created bool, err error)
+	// Complete stores the response for a claimed key for ttl
+	Complete(ctx context.Context, key string, response IdempotencyResponse, ttl time.Duration) error
+	// Release drops an unfinished claim so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// memoryIdempotencyEntry is a key's record and when it expires
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in process. Keys are only
+// recognised by the replica that saw them first.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries:   make(map[string]*memoryIdempotencyEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Begin implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key, requestHash string, lockTimeout time.Duration) (*IdempotencyRecord, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	if entry, exists := s.entries[key]; exists && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, false, nil
+	}
+
+	entry := &memoryIdempotencyEntry{
+		record:    IdempotencyRecord{RequestHash: requestHash},
+		expiresAt: now.Add(lockTimeout),
+	}
+	s.entries[key] = entry
+	record := entry.record
+	return &record, true, nil
+}
+
+// Complete implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, response IdempotencyResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &memoryIdempotencyEntry{}
+		s.entries[key] = entry
+	}
+	entry.record.Completed = true
+	entry.record.Response = IdempotencyResponse{
+		Status: response.Status,
+		Header: response.Header.Clone(),
+		Body:   append([]byte(nil), response.Body...),
+	}
+	entry.expiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+// Release implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.entries[key]; exists && !entry.record.Completed {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of keys held
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// sweepLocked drops expired keys at most once a minute. Callers hold mu.
+func (s *MemoryIdempotencyStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// IPFilterConfig holds configuration for IP filtering middleware
+type IPFilterConfig struct {
+	Allow     []string // CIDRs or IPs allowed (empty = all not denied)
+	Deny      []string // CIDRs or IPs denied; takes precedence over Allow
+	SkipPaths []string
+}
+
+// NewIPFilterMiddleware creates a middleware that rejects clients outside
+// the allow list or inside the deny list with 403. The client address is
+// c.ClientIP(), so put it after the proxy headers middleware when behind
+// proxies. It panics if a range is not a valid CIDR or IP.
+func NewIPFilterMiddleware(config IPFilterConfig) gin.HandlerFunc {
+	allow, err := utils.ParseCIDRs(config.Allow)
+	if err != nil {
+		panic(fmt.Sprintf("invalid IP allow list: %v", err))
+	}
+	deny, err := utils.ParseCIDRs(config.Deny)
+	if err != nil {
+		panic(fmt.Sprintf("invalid IP deny list: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		if shouldSkipAuth(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		addr, err := netip.ParseAddr(c.ClientIP())
+		if err != nil || utils.PrefixesContain(deny, addr) ||
+			(len(allow) > 0 && !utils.PrefixesContain(allow, addr)) {
+			Logger(c).Warn("request rejected by IP filter", slog.String("client_ip", c.ClientIP()))
+			responses.Forbidden(c, "Access denied")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AllowIPs creates an IP filter that only admits the given CIDRs or IPs
+func AllowIPs(cidrs ...string) gin.HandlerFunc {
+	return NewIPFilterMiddleware(IPFilterConfig{Allow: cidrs})
+}
+
+// DenyIPs creates an IP filter that rejects the given CIDRs or IPs
+func DenyIPs(cidrs ...string) gin.HandlerFunc {
+	return NewIPFilterMiddleware(IPFilterConfig{Deny: cidrs})
+}
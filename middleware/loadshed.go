@@ -0,0 +1,396 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// LoadSheddingMetric is the expvar map load shedder stats are published
+// in, keyed by shedder name
+const LoadSheddingMetric = "load_shedding"
+
+// LoadSheddingConfig holds configuration for load shedding middleware
+type LoadSheddingConfig struct {
+	Name          string         // Name in metrics and health checks (default: "http")
+	MaxConcurrent int            // In-flight requests allowed across all routes (default: 100)
+	RouteLimits   map[string]int // In-flight caps for route templates, "/path" or "GET /path"
+
+	QueueSize    int           // Requests that may wait for a slot (default: 50, -1 disables queueing)
+	QueueTimeout time.Duration // How long a request may wait (default: 100ms)
+	RetryAfter   time.Duration // Advertised in Retry-After when shedding (default: 1s)
+
+	SkipPaths []string
+
+	// Adaptive adjusts MaxConcurrent from observed latency (nil keeps it fixed)
+	Adaptive *AdaptiveLimitConfig
+}
+
+// AdaptiveLimitConfig configures additive-increase/multiplicative-decrease
+// adjustment of the concurrency limit
+type AdaptiveLimitConfig struct {
+	MinLimit       int           // Lower bound (default: 1)
+	MaxLimit       int           // Upper bound (default: 10x MaxConcurrent)
+	TargetLatency  time.Duration // Requests slower than this shrink the limit (default: 500ms)
+	DecreaseFactor float64       // Multiplier applied on slow requests (default: 0.9)
+}
+
+// DefaultLoadSheddingConfig returns default load shedding configuration
+func DefaultLoadSheddingConfig() LoadSheddingConfig {
+	return LoadSheddingConfig{
+		Name:          "http",
+		MaxConcurrent: 100,
+		QueueSize:     50,
+		QueueTimeout:  100 * time.Millisecond,
+		RetryAfter:    time.Second,
+		SkipPaths:     []string{"/health", "/ready", "/metrics"},
+	}
+}
+
+// LoadSheddingStats is a snapshot of a load shedder
+type LoadSheddingStats struct {
+	Name     string `json:"name"`
+	Limit    int    `json:"limit"`
+	InFlight int    `json:"in_flight"`
+	Queued   int    `json:"queued"`
+	Served   int64  `json:"served"`
+	Rejected int64  `json:"rejected"`
+}
+
+// errShed is returned when a limiter has no slot for a request
+var errShed = errors.New("load shed")
+
+// LoadShedder caps in-flight requests, briefly queueing the excess and
+// rejecting the rest with 503 so latency stays bounded under spikes
+type LoadShedder struct {
+	config   LoadSheddingConfig
+	global   *concurrencyLimiter
+	routes   map[string]*concurrencyLimiter
+	adaptive *aimdController
+
+	served       atomic.Int64
+	rejected     atomic.Int64
+	lastRejected atomic.Int64 // Unix nanoseconds
+}
+
+// NewLoadShedder creates a load shedder, using defaults for zero values.
+// Its stats are published under LoadSheddingMetric.
+func NewLoadShedder(config LoadSheddingConfig) *LoadShedder {
+	defaults := DefaultLoadSheddingConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = defaults.QueueSize
+	} else if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = defaults.QueueTimeout
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaults.RetryAfter
+	}
+
+	ls := &LoadShedder{
+		config: config,
+		global: newConcurrencyLimiter(config.MaxConcurrent, config.QueueSize),
+		routes: make(map[string]*concurrencyLimiter, len(config.RouteLimits)),
+	}
+	for route, limit := range config.RouteLimits {
+		ls.routes[route] = newConcurrencyLimiter(limit, config.QueueSize)
+	}
+	if config.Adaptive != nil {
+		ls.adaptive = newAIMDController(*config.Adaptive, config.MaxConcurrent)
+	}
+
+	publishLoadShedder(ls)
+	return ls
+}
+
+// NewLoadSheddingMiddleware creates a load shedding middleware
+func NewLoadSheddingMiddleware(config LoadSheddingConfig) gin.HandlerFunc {
+	return NewLoadShedder(config).Middleware()
+}
+
+// LoadSheddingMiddleware caps in-flight requests at maxConcurrent
+func LoadSheddingMiddleware(maxConcurrent int) gin.HandlerFunc {
+	config := DefaultLoadSheddingConfig()
+	config.MaxConcurrent = maxConcurrent
+	return NewLoadSheddingMiddleware(config)
+}
+
+// Middleware returns the load shedding middleware
+func (ls *LoadShedder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if shouldSkipAuth(c.Request.URL.Path, ls.config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), ls.config.QueueTimeout)
+		defer cancel()
+
+		if route := ls.routeLimiter(c); route != nil {
+			if err := route.acquire(ctx); err != nil {
+				ls.shed(c)
+				return
+			}
+			defer route.release()
+		}
+
+		if err := ls.global.acquire(ctx); err != nil {
+			ls.shed(c)
+			return
+		}
+		defer ls.global.release()
+
+		start := time.Now()
+		c.Next()
+		ls.served.Add(1)
+
+		if ls.adaptive != nil {
+			if limit, changed := ls.adaptive.observe(time.Since(start), ls.global.inFlight()); changed {
+				ls.global.setLimit(limit)
+			}
+		}
+	}
+}
+
+// Stats returns a snapshot of the load shedder
+func (ls *LoadShedder) Stats() LoadSheddingStats {
+	limit, inFlight, queued := ls.global.stats()
+	return LoadSheddingStats{
+		Name:     ls.config.Name,
+		Limit:    limit,
+		InFlight: inFlight,
+		Queued:   queued,
+		Served:   ls.served.Load(),
+		Rejected: ls.rejected.Load(),
+	}
+}
+
+// HealthChecker reports the load shedder as degraded while it is saturated
+// or if it rejected requests in the last 30 seconds
+func (ls *LoadShedder) HealthChecker() HealthChecker {
+	return func() HealthCheck {
+		stats := ls.Stats()
+
+		check := HealthCheck{
+			Name:   ls.config.Name,
+			Status: HealthStatusHealthy,
+			Metadata: map[string]interface{}{
+				"limit":     stats.Limit,
+				"in_flight": stats.InFlight,
+				"queued":    stats.Queued,
+				"rejected":  stats.Rejected,
+			},
+		}
+
+		if last := ls.lastRejected.Load(); last > 0 && time.Since(time.Unix(0, last)) < 30*time.Second {
+			check.Status = HealthStatusDegraded
+			check.Message = "Shedding load"
+		} else if stats.InFlight >= stats.Limit {
+			check.Status = HealthStatusDegraded
+			check.Message = "At concurrency limit"
+		}
+
+		return check
+	}
+}
+
+// RegisterHealthCheck adds the load shedder's health checker to config
+func (ls *LoadShedder) RegisterHealthCheck(config *HealthConfig) {
+	config.AddHealthChecker("load_shedding:"+ls.config.Name, ls.HealthChecker())
+}
+
+// shed rejects the request with 503 and Retry-After
+func (ls *LoadShedder) shed(c *gin.Context) {
+	ls.rejected.Add(1)
+	ls.lastRejected.Store(time.Now().UnixNano())
+
+	c.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(ls.config.RetryAfter))))
+	responses.ServiceUnavailable(c, "Service is overloaded, retry later")
+	c.Abort()
+}
+
+// routeLimiter returns the limiter for the request's route, if any
+func (ls *LoadShedder) routeLimiter(c *gin.Context) *concurrencyLimiter {
+	if len(ls.routes) == 0 {
+		return nil
+	}
+	route := c.FullPath()
+	if limiter, ok := ls.routes[c.Request.Method+" "+route]; ok {
+		return limiter
+	}
+	return ls.routes[route]
+}
+
+// concurrencyLimiter is a resizable semaphore with a bounded FIFO queue
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	active   int
+	maxQueue int
+	waiters  []chan struct{}
+}
+
+func newConcurrencyLimiter(limit, maxQueue int) *concurrencyLimiter {
+	return &concurrencyLimiter{limit: limit, maxQueue: maxQueue}
+}
+
+// acquire takes a slot, waiting in the queue until ctx is done
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.active < l.limit {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.waiters) >= l.maxQueue {
+		l.mu.Unlock()
+		return errShed
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, waiter := range l.waiters {
+		if waiter == ready {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return errShed
+		}
+	}
+	// The slot was handed over while timing out, so keep it
+	return nil
+}
+
+// release frees a slot, handing it to the next waiter if the limit allows
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	l.wakeLocked()
+}
+
+// setLimit changes the limit, admitting waiters if it grew
+func (l *concurrencyLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = limit
+	l.wakeLocked()
+}
+
+// wakeLocked hands free slots to waiters in order. Callers hold mu.
+func (l *concurrencyLimiter) wakeLocked() {
+	for l.active < l.limit && len(l.waiters) > 0 {
+		l.active++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+func (l *concurrencyLimiter) inFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active
+}
+
+func (l *concurrencyLimiter) stats() (limit, active, queued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.active, len(l.waiters)
+}
+
+// aimdController grows the limit by one per limit's worth of fast requests
+// while it is being used, and shrinks it multiplicatively, at most once per
+// target latency, when requests are slow
+type aimdController struct {
+	config AdaptiveLimitConfig
+
+	mu           sync.Mutex
+	limit        float64
+	lastDecrease time.Time
+}
+
+func newAIMDController(config AdaptiveLimitConfig, initial int) *aimdController {
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 10 * initial
+	}
+	if config.TargetLatency <= 0 {
+		config.TargetLatency = 500 * time.Millisecond
+	}
+	if config.DecreaseFactor <= 0 || config.DecreaseFactor >= 1 {
+		config.DecreaseFactor = 0.9
+	}
+
+	return &aimdController{config: config, limit: float64(initial)}
+}
+
+// observe records a request's latency and returns the new limit
+func (a *aimdController) observe(latency time.Duration, inFlight int) (int, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := int(a.limit)
+	now := time.Now()
+
+	if latency > a.config.TargetLatency {
+		if now.Sub(a.lastDecrease) < a.config.TargetLatency {
+			return previous, false
+		}
+		a.lastDecrease = now
+		a.limit = math.Max(float64(a.config.MinLimit), a.limit*a.config.DecreaseFactor)
+	} else if float64(inFlight) >= a.limit/2 {
+		// Only grow while the current limit is actually being used
+		a.limit = math.Min(float64(a.config.MaxLimit), a.limit+1/a.limit)
+	}
+
+	current := int(a.limit)
+	return current, current != previous
+}
+
+// loadSheddingVars holds the published stats of every load shedder
+var (
+	loadSheddingOnce sync.Once
+	loadSheddingVars *expvar.Map
+)
+
+// publishLoadShedder publishes the shedder's stats under its name
+func publishLoadShedder(ls *LoadShedder) {
+	loadSheddingOnce.Do(func() {
+		if existing, ok := expvar.Get(LoadSheddingMetric).(*expvar.Map); ok {
+			loadSheddingVars = existing
+			return
+		}
+		loadSheddingVars = expvar.NewMap(LoadSheddingMetric)
+	})
+
+	loadSheddingVars.Set(ls.config.Name, expvar.Func(func() any {
+		return ls.Stats()
+	}))
+}
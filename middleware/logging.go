@@ -1,9 +1,19 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,10 +27,50 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// AccessLogFormat selects how access log records are written
+type AccessLogFormat string
+
+const (
+	// AccessLogDefault writes records through the process logger, so they
+	// follow LOG_FORMAT and the runtime log level
+	AccessLogDefault AccessLogFormat = ""
+	// AccessLogJSON writes one JSON object per request
+	AccessLogJSON AccessLogFormat = "json"
+	// AccessLogLogfmt writes one key=value line per request
+	AccessLogLogfmt AccessLogFormat = "logfmt"
+	// AccessLogCombined writes Apache combined log format lines followed by
+	// the request ID and latency in milliseconds
+	AccessLogCombined AccessLogFormat = "combined"
+)
+
+// accessLogMessage is the message of every structured access log record
+const accessLogMessage = "request"
+
 // LoggingConfig holds configuration for logging middleware
 type LoggingConfig struct {
-	Level        LogLevel
-	SkipPaths    []string
+	// Level is the minimum level a request must reach to be logged.
+	// Requests are logged at error for 5xx, warn for 4xx or slow requests
+	// and info otherwise.
+	Level     LogLevel
+	Format    AccessLogFormat
+	Output    io.Writer // Used by JSON, logfmt and combined formats (default: os.Stdout)
+	SkipPaths []string
+
+	// SampleRates maps a path or route template to the fraction (0-1) of
+	// successful requests that are logged. Requests with a 4xx or 5xx
+	// status, or slower than SlowThreshold, are always logged.
+	SampleRates map[string]float64
+
+	// SlowThreshold logs successful requests slower than this at warn
+	// level (0 disables)
+	SlowThreshold time.Duration
+
+	// Body configures optional request and response body capture. Its
+	// RedactFields also mask query parameters (default: those of
+	// DefaultBodyLogConfig), whether or not bodies are captured.
+	Body BodyLogConfig
+
+	// CustomFormat keeps the legacy gin formatter behaviour when set
 	CustomFormat gin.LogFormatter
 }
 
@@ -28,13 +78,41 @@ type LoggingConfig struct {
 func DefaultLoggingConfig() LoggingConfig {
 	return LoggingConfig{
 		Level:     LogLevelInfo,
+		Format:    AccessLogDefault,
 		SkipPaths: []string{"/health", "/metrics"},
 	}
 }
 
+// AccessLogEntry holds the fields recorded for a single request
+type AccessLogEntry struct {
+	Time      time.Time
+	RequestID string
+	TraceID   string
+	Method    string
+	Path      string
+	Route     string
+	Query     string
+	Proto     string
+	Status    int
+	BytesIn   int64
+	BytesOut  int
+	Latency   time.Duration
+	ClientIP  string
+	UserAgent string
+	Referer   string
+	UserID    string
+	Error     string
+
+	// Populated only when body capture is enabled
+	RequestBody     string
+	ResponseBody    string
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+}
+
 // NewLoggingMiddleware creates a new logging middleware with the given configuration
 func NewLoggingMiddleware(config LoggingConfig) gin.HandlerFunc {
-	// Use custom format if provided, otherwise use default
+	// Use custom format if provided
 	if config.CustomFormat != nil {
 		return gin.LoggerWithConfig(gin.LoggerConfig{
 			Formatter: config.CustomFormat,
@@ -42,10 +120,87 @@ func NewLoggingMiddleware(config LoggingConfig) gin.HandlerFunc {
 		})
 	}
 
-	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: defaultLogFormat,
-		SkipPaths: config.SkipPaths,
-	})
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+
+	minLevel := toSlogLevel(config.Level)
+	write := newAccessLogWriter(config)
+
+	redaction := config.Body
+	if len(redaction.RedactFields) == 0 {
+		redaction.RedactFields = DefaultBodyLogConfig().RedactFields
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if skip[path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		capture := config.Body.captureBodies(c, c.FullPath())
+
+		var bytesIn *countingReadCloser
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			bytesIn = &countingReadCloser{ReadCloser: c.Request.Body}
+			c.Request.Body = bytesIn
+		}
+
+		c.Next()
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+
+		level := accessLogLevel(status, latency, config.SlowThreshold)
+		if level < minLevel {
+			return
+		}
+
+		route := c.FullPath()
+		if level < slog.LevelWarn && !sampled(config.SampleRates, path, route) {
+			return
+		}
+
+		entry := AccessLogEntry{
+			Time:      start,
+			RequestID: MustGetRequestID(c),
+			TraceID:   tracing.TraceIDFromContext(c.Request.Context()),
+			Method:    c.Request.Method,
+			Path:      path,
+			Route:     route,
+			Query:     redaction.redactQuery(c.Request.URL.RawQuery),
+			Proto:     c.Request.Proto,
+			Status:    status,
+			BytesOut:  c.Writer.Size(),
+			Latency:   latency,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Referer:   c.Request.Referer(),
+			Error:     c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+
+		if bytesIn != nil {
+			entry.BytesIn = bytesIn.n.Load()
+		}
+		// Fall back to the declared length when the handler did not read the body
+		if entry.BytesIn == 0 && c.Request.ContentLength > 0 {
+			entry.BytesIn = c.Request.ContentLength
+		}
+		if entry.BytesOut < 0 {
+			entry.BytesOut = 0
+		}
+		if userID, ok := GetUserID(c); ok {
+			entry.UserID = userID
+		}
+		if capture != nil {
+			capture.entryFields(c, &entry)
+		}
+
+		write(c.Request.Context(), level, entry)
+	}
 }
 
 // DefaultLoggingMiddleware creates a logging middleware with default configuration
@@ -53,159 +208,241 @@ func DefaultLoggingMiddleware() gin.HandlerFunc {
 	return NewLoggingMiddleware(DefaultLoggingConfig())
 }
 
-// DetailedLoggingMiddleware creates a logging middleware with detailed output
+// DetailedLoggingMiddleware creates a logging middleware writing logfmt
+// records for every request, including debug-level ones
 func DetailedLoggingMiddleware() gin.HandlerFunc {
-	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: detailedLogFormat,
+	return NewLoggingMiddleware(LoggingConfig{
+		Level:     LogLevelDebug,
+		Format:    AccessLogLogfmt,
 		SkipPaths: []string{"/health"},
 	})
 }
 
-// ProductionLoggingMiddleware creates a logging middleware optimized for production
+// ProductionLoggingMiddleware creates a logging middleware optimized for
+// production: JSON records with health and metrics probes sampled at 1%
 func ProductionLoggingMiddleware() gin.HandlerFunc {
-	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: productionLogFormat,
-		SkipPaths: []string{"/health", "/metrics"},
+	return NewLoggingMiddleware(LoggingConfig{
+		Level:  LogLevelInfo,
+		Format: AccessLogJSON,
+		SampleRates: map[string]float64{
+			"/health":  0.01,
+			"/ready":   0.01,
+			"/live":    0.01,
+			"/metrics": 0.01,
+		},
+		SlowThreshold: time.Second,
 	})
 }
 
-// SilentLoggingMiddleware creates a logging middleware that only logs errors
+// SilentLoggingMiddleware creates a logging middleware that only logs
+// errors and slow requests
 func SilentLoggingMiddleware() gin.HandlerFunc {
-	return gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: func(param gin.LogFormatterParams) string {
-			// Only log errors and slow requests
-			if param.StatusCode >= 400 || param.Latency > time.Second {
-				return fmt.Sprintf("[%s] %s %s %d %s %s\n",
-					param.TimeStamp.Format("2006/01/02 - 15:04:05"),
-					param.Method,
-					param.Path,
-					param.StatusCode,
-					param.Latency,
-					param.ErrorMessage,
-				)
-			}
-			return ""
-		},
-		SkipPaths: []string{"/health", "/metrics"},
+	return NewLoggingMiddleware(LoggingConfig{
+		Level:         LogLevelWarn,
+		SkipPaths:     []string{"/health", "/metrics"},
+		SlowThreshold: time.Second,
 	})
 }
 
-// defaultLogFormat provides a clean, readable log format
-func defaultLogFormat(param gin.LogFormatterParams) string {
-	var statusColor, methodColor, resetColor string
-	if param.IsOutputColor() {
-		statusColor = param.StatusCodeColor()
-		methodColor = param.MethodColor()
-		resetColor = param.ResetColor()
+// RequestLogger logs requests at or above the given level through the
+// process logger
+func RequestLogger(level LogLevel) gin.HandlerFunc {
+	return NewLoggingMiddleware(LoggingConfig{Level: level})
+}
+
+// accessLogLevel returns the level for a request based on its status and latency
+func accessLogLevel(status int, latency, slowThreshold time.Duration) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	case slowThreshold > 0 && latency > slowThreshold:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
 	}
+}
 
-	if param.Latency > time.Minute {
-		param.Latency = param.Latency.Truncate(time.Second)
+// sampled reports whether a successful request should be logged. The route
+// template takes precedence over the raw path.
+func sampled(rates map[string]float64, path, route string) bool {
+	if len(rates) == 0 {
+		return true
 	}
 
-	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
-		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
-		statusColor, param.StatusCode, resetColor,
-		param.Latency,
-		param.ClientIP,
-		methodColor, param.Method, resetColor,
-		param.Path,
-		param.ErrorMessage,
-	)
-}
+	rate, ok := rates[route]
+	if route == "" || !ok {
+		rate, ok = rates[path]
+	}
+	if !ok {
+		return true
+	}
 
-// detailedLogFormat provides detailed logging with request/response info
-func detailedLogFormat(param gin.LogFormatterParams) string {
-	return fmt.Sprintf(`[%s] "%s %s %s" %d %s "%s" "%s" %s %s
-`,
-		param.TimeStamp.Format("2006/01/02 15:04:05"),
-		param.Method,
-		param.Path,
-		param.Request.Proto,
-		param.StatusCode,
-		param.Latency,
-		param.Request.UserAgent(),
-		param.Request.Referer(),
-		param.ClientIP,
-		param.ErrorMessage,
-	)
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
 }
 
-// productionLogFormat provides JSON-like structured logging for production
-func productionLogFormat(param gin.LogFormatterParams) string {
-	return fmt.Sprintf(`{"time":"%s","method":"%s","path":"%s","status":%d,"latency":"%s","ip":"%s","user_agent":"%s","error":"%s"}
-`,
-		param.TimeStamp.Format(time.RFC3339),
-		param.Method,
-		param.Path,
-		param.StatusCode,
-		param.Latency,
-		param.ClientIP,
-		param.Request.UserAgent(),
-		param.ErrorMessage,
-	)
+// toSlogLevel converts a middleware log level to a slog level
+func toSlogLevel(level LogLevel) slog.Level {
+	switch LogLevel(strings.ToLower(string(level))) {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
-// RequestLogger logs individual request details
-func RequestLogger(level LogLevel) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
-
-		// Process request
-		c.Next()
+// accessLogWriter writes a single access log record
+type accessLogWriter func(ctx context.Context, level slog.Level, entry AccessLogEntry)
 
-		// Calculate latency
-		latency := time.Since(start)
+// newAccessLogWriter returns the writer for the configured format
+func newAccessLogWriter(config LoggingConfig) accessLogWriter {
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
 
-		// Get request details
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
+	switch config.Format {
+	case AccessLogCombined:
+		return func(_ context.Context, _ slog.Level, entry AccessLogEntry) {
+			fmt.Fprintln(output, formatCombined(entry))
+		}
+	case AccessLogJSON, AccessLogLogfmt:
+		// The middleware applies its own minimum level, so the handler
+		// accepts everything it is given
+		opts := &slog.HandlerOptions{Level: slog.LevelDebug}
 
-		if raw != "" {
-			path = path + "?" + raw
+		var handler slog.Handler
+		if config.Format == AccessLogJSON {
+			handler = slog.NewJSONHandler(output, opts)
+		} else {
+			handler = slog.NewTextHandler(output, opts)
 		}
 
-		// Log based on level and status
-		switch {
-		case statusCode >= 500:
-			fmt.Printf("[ERROR] %s | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
-		case statusCode >= 400:
-			fmt.Printf("[WARN] %s | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
-		case level == LogLevelDebug:
-			fmt.Printf("[DEBUG] %s | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
-		case level == LogLevelInfo && statusCode < 400:
-			fmt.Printf("[INFO] %s | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
+		logger := slog.New(handler)
+		return func(ctx context.Context, level slog.Level, entry AccessLogEntry) {
+			logger.LogAttrs(ctx, level, accessLogMessage, entry.attrs()...)
+		}
+	default:
+		return func(ctx context.Context, level slog.Level, entry AccessLogEntry) {
+			logging.Default().LogAttrs(ctx, level, accessLogMessage, entry.attrs()...)
 		}
 	}
 }
+
+// attrs returns the structured fields of the entry
+func (e AccessLogEntry) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, 16)
+
+	if e.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", e.RequestID))
+	}
+	if e.TraceID != "" {
+		attrs = append(attrs, slog.String("trace_id", e.TraceID))
+	}
+
+	attrs = append(attrs,
+		slog.String("method", e.Method),
+		slog.String("path", e.Path),
+	)
+	if e.Route != "" {
+		attrs = append(attrs, slog.String("route", e.Route))
+	}
+	if e.Query != "" {
+		attrs = append(attrs, slog.String("query", e.Query))
+	}
+
+	attrs = append(attrs,
+		slog.String("proto", e.Proto),
+		slog.Int("status", e.Status),
+		slog.Int64("bytes_in", e.BytesIn),
+		slog.Int("bytes_out", e.BytesOut),
+		slog.Float64("latency_ms", float64(e.Latency.Microseconds())/1000),
+		slog.String("client_ip", e.ClientIP),
+		slog.String("user_agent", e.UserAgent),
+	)
+
+	if e.Referer != "" {
+		attrs = append(attrs, slog.String("referer", e.Referer))
+	}
+	if e.UserID != "" {
+		attrs = append(attrs, slog.String("user_id", e.UserID))
+	}
+	if e.Error != "" {
+		attrs = append(attrs, slog.String("error", e.Error))
+	}
+	if e.RequestBody != "" {
+		attrs = append(attrs, slog.String("request_body", e.RequestBody))
+	}
+	if e.ResponseBody != "" {
+		attrs = append(attrs, slog.String("response_body", e.ResponseBody))
+	}
+	if len(e.RequestHeaders) > 0 {
+		attrs = append(attrs, slog.Any("request_headers", e.RequestHeaders))
+	}
+	if len(e.ResponseHeaders) > 0 {
+		attrs = append(attrs, slog.Any("response_headers", e.ResponseHeaders))
+	}
+
+	return attrs
+}
+
+// formatCombined renders the entry in Apache combined log format with the
+// request ID and latency appended
+func formatCombined(e AccessLogEntry) string {
+	requestURI := e.Path
+	if e.Query != "" {
+		requestURI += "?" + e.Query
+	}
+
+	bytesOut := "-"
+	if e.BytesOut > 0 {
+		bytesOut = fmt.Sprintf("%d", e.BytesOut)
+	}
+
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s "%s" "%s" "%s" %.3f`,
+		e.ClientIP,
+		orDash(e.UserID),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.Method,
+		requestURI,
+		e.Proto,
+		e.Status,
+		bytesOut,
+		orDash(e.Referer),
+		orDash(e.UserAgent),
+		orDash(e.RequestID),
+		float64(e.Latency.Microseconds())/1000,
+	)
+}
+
+// orDash returns "-" for empty combined log fields
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// countingReadCloser counts the bytes read from a request body
+type countingReadCloser struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
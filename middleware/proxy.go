@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ProxyHeadersConfig holds configuration for proxy headers middleware
+type ProxyHeadersConfig struct {
+	// Proxies whose headers are trusted, as CIDRs or IPs. Headers from any
+	// other peer are ignored.
+	TrustedProxies []string
+}
+
+// ProxyHeadersConfigFromConfig returns proxy headers configuration from
+// TRUSTED_PROXIES
+func ProxyHeadersConfigFromConfig(cfg *config.Config) ProxyHeadersConfig {
+	return ProxyHeadersConfig{TrustedProxies: cfg.ServerConfig.TrustedProxies}
+}
+
+// NewProxyHeadersMiddleware creates a middleware that resolves the real
+// client address, scheme and host of requests received through trusted
+// proxies, from the Forwarded header (RFC 7239) or, if absent,
+// X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host.
+//
+// The client address replaces c.Request.RemoteAddr, so c.ClientIP() and
+// everything built on it see the client, and the scheme and host are set on
+// c.Request.URL and c.Request.Host for building absolute URLs. It panics if
+// a trusted proxy is not a valid CIDR or IP.
+func NewProxyHeadersMiddleware(config ProxyHeadersConfig) gin.HandlerFunc {
+	trusted, err := utils.ParseCIDRs(config.TrustedProxies)
+	if err != nil {
+		panic(fmt.Sprintf("invalid trusted proxies: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		if len(trusted) > 0 {
+			applyForwardedHeaders(c, trusted)
+		}
+		c.Next()
+	}
+}
+
+// ProxyHeadersMiddleware trusts forwarding headers from trustedProxies
+func ProxyHeadersMiddleware(trustedProxies []string) gin.HandlerFunc {
+	return NewProxyHeadersMiddleware(ProxyHeadersConfig{TrustedProxies: trustedProxies})
+}
+
+// forwardedHop is what a proxy recorded about the request it received
+type forwardedHop struct {
+	client string // Address the proxy received the request from
+	proto  string
+	host   string
+}
+
+// applyForwardedHeaders rewrites the request from the hop added by the
+// outermost trusted proxy
+func applyForwardedHeaders(c *gin.Context, trusted []netip.Prefix) {
+	remote, ok := parseHostAddr(c.Request.RemoteAddr)
+	if !ok || !utils.PrefixesContain(trusted, remote) {
+		return
+	}
+
+	var hops []forwardedHop
+	if values := c.Request.Header.Values("Forwarded"); len(values) > 0 {
+		hops = parseForwarded(values)
+	} else {
+		hops = parseXForwarded(c.Request.Header)
+	}
+	if len(hops) == 0 {
+		return
+	}
+
+	// Walk back from the nearest proxy until an untrusted address: that is
+	// the client, and the hop recording it was added by the outermost
+	// trusted proxy
+	hop := hops[0]
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop = hops[i]
+		addr, ok := parseHostAddr(hop.client)
+		if !ok || !utils.PrefixesContain(trusted, addr) {
+			break
+		}
+	}
+
+	if addr, ok := parseHostAddr(hop.client); ok {
+		c.Request.RemoteAddr = net.JoinHostPort(addr.String(), "0")
+	}
+	if proto := strings.ToLower(hop.proto); proto == "http" || proto == "https" {
+		c.Request.URL.Scheme = proto
+	}
+	if hop.host != "" {
+		c.Request.Host = hop.host
+		c.Request.URL.Host = hop.host
+	}
+}
+
+// parseForwarded parses Forwarded header values into hops, nearest last
+func parseForwarded(values []string) []forwardedHop {
+	var hops []forwardedHop
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var hop forwardedHop
+			for _, pair := range strings.Split(element, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if !found {
+					continue
+				}
+				val = strings.Trim(strings.TrimSpace(val), `"`)
+				switch strings.ToLower(key) {
+				case "for":
+					hop.client = val
+				case "proto":
+					hop.proto = val
+				case "host":
+					hop.host = val
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// parseXForwarded parses X-Forwarded-* headers into hops, nearest last.
+// The scheme and host apply to the hop added by the outermost proxy.
+func parseXForwarded(header map[string][]string) []forwardedHop {
+	var hops []forwardedHop
+	for _, value := range header["X-Forwarded-For"] {
+		for _, client := range strings.Split(value, ",") {
+			hops = append(hops, forwardedHop{client: strings.TrimSpace(client)})
+		}
+	}
+	if len(hops) == 0 {
+		return nil
+	}
+
+	proto := firstHeaderValue(header["X-Forwarded-Proto"])
+	host := firstHeaderValue(header["X-Forwarded-Host"])
+	for i := range hops {
+		hops[i].proto = proto
+		hops[i].host = host
+	}
+	return hops
+}
+
+// firstHeaderValue returns the first comma-separated value
+func firstHeaderValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	first, _, _ := strings.Cut(values[0], ",")
+	return strings.TrimSpace(first)
+}
+
+// parseHostAddr parses an IP address with an optional port, including
+// bracketed IPv6 addresses
+func parseHostAddr(value string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
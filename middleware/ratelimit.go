@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limit response headers
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimitAlgorithm selects how requests are counted
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket refills continuously and allows bursts up to Burst
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+	// SlidingWindow approximates a rolling window from the current and
+	// previous fixed windows
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimit is a limit of Requests per Window. A zero Requests means
+// unlimited.
+type RateLimit struct {
+	Requests  int
+	Window    time.Duration
+	Burst     int                // Token bucket capacity (default: Requests)
+	Algorithm RateLimitAlgorithm // Default: the config's algorithm
+}
+
+// RateLimitResult is the outcome of taking a request from a limit
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the limit is fully replenished
+	RetryAfter time.Duration // Until the next request is allowed, if denied
+}
+
+// RateLimitKeyFunc returns the key requests are counted under. An empty key
+// skips rate limiting for the request.
+type RateLimitKeyFunc func(*gin.Context) string
+
+// RateLimitConfig holds configuration for rate limiting middleware
+type RateLimitConfig struct {
+	Algorithm RateLimitAlgorithm
+	Limit     RateLimit
+	KeyFunc   RateLimitKeyFunc
+	Store     RateLimitStore // Default: a new in-memory store
+
+	// Routes overrides the limit for route templates, given as
+	// "/users/:id" or "GET /users/:id". Overridden routes are counted
+	// separately from the default limit.
+	Routes map[string]RateLimit
+
+	SkipPaths []string
+	OnLimited func(*gin.Context, RateLimitResult) // Default: 429 error response
+}
+
+// DefaultRateLimitConfig returns a token bucket of 100 requests per minute
+// per client IP
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Algorithm: TokenBucket,
+		Limit:     RateLimit{Requests: 100, Window: time.Minute},
+		KeyFunc:   KeyByIP,
+		SkipPaths: []string{"/health", "/ready", "/metrics"},
+	}
+}
+
+// NewRateLimitMiddleware creates a rate limiting middleware
+func NewRateLimitMiddleware(config RateLimitConfig) gin.HandlerFunc {
+	if config.Algorithm == "" {
+		config.Algorithm = TokenBucket
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore(DefaultMemoryRateLimitStoreConfig())
+	}
+	if config.OnLimited == nil {
+		config.OnLimited = defaultRateLimitHandler
+	}
+
+	return func(c *gin.Context) {
+		if shouldSkipAuth(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		limit, scope := config.limitFor(c)
+		if limit.Requests <= 0 || limit.Window <= 0 {
+			c.Next()
+			return
+		}
+		if limit.Algorithm == "" {
+			limit.Algorithm = config.Algorithm
+		}
+
+		key := config.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		result, err := config.Store.Take(c.Request.Context(), scope+"|"+key, limit)
+		if err != nil {
+			// Fail open: an unavailable store should not take the service down
+			Logger(c).Warn("rate limit store failed", logging.Err(err))
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, result)
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+			config.OnLimited(c, result)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware limits each client IP to requests per window
+func RateLimitMiddleware(requests int, window time.Duration) gin.HandlerFunc {
+	config := DefaultRateLimitConfig()
+	config.Limit = RateLimit{Requests: requests, Window: window}
+	return NewRateLimitMiddleware(config)
+}
+
+// limitFor returns the limit that applies to the request and the scope its
+// requests are counted in
+func (config *RateLimitConfig) limitFor(c *gin.Context) (RateLimit, string) {
+	route := c.FullPath()
+	if route != "" && len(config.Routes) > 0 {
+		if limit, ok := config.Routes[c.Request.Method+" "+route]; ok {
+			return limit, c.Request.Method + " " + route
+		}
+		if limit, ok := config.Routes[route]; ok {
+			return limit, route
+		}
+	}
+	return config.Limit, "*"
+}
+
+// KeyByIP counts requests per client IP
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByAPIKey counts requests per API key sent in header, falling back to
+// the client IP. Keys are hashed so they are not held in the store.
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		apiKey := c.GetHeader(header)
+		if apiKey == "" {
+			return KeyByIP(c)
+		}
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// KeyByUserID counts requests per authenticated user, falling back to the
+// client IP. Authentication middleware must run first.
+func KeyByUserID(c *gin.Context) string {
+	if userID, ok := GetUserID(c); ok && userID != "" {
+		return "user:" + userID
+	}
+	return KeyByIP(c)
+}
+
+// KeyByRoute counts all requests to a route together, regardless of client
+func KeyByRoute(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	return "route:" + c.Request.Method + " " + route
+}
+
+// setRateLimitHeaders writes the RateLimit-* headers
+func setRateLimitHeaders(c *gin.Context, result RateLimitResult) {
+	c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// defaultRateLimitHandler sends a 429 error response
+func defaultRateLimitHandler(c *gin.Context, result RateLimitResult) {
+	responses.Error(c, http.StatusTooManyRequests, responses.ErrCodeTooManyRequests, "Rate limit exceeded, retry later")
+}
+
+// ceilSeconds rounds a positive duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
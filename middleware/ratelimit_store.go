@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitStore holds rate limit state. Implementations backed by a shared
+// store let replicas enforce a single limit.
+type RateLimitStore interface {
+	// Take counts a request against key and reports whether it is allowed
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitState is the per-key state of both algorithms. Stores persist it
+// and apply Take atomically per key.
+type RateLimitState struct {
+	Tokens      float64   // Token bucket: tokens available at Updated
+	Updated     time.Time // Token bucket: time of the last refill
+	WindowStart time.Time // Sliding window: start of the current window
+	Count       int       // Sliding window: requests in the current window
+	PrevCount   int       // Sliding window: requests in the previous window
+}
+
+// Take counts a request at now, updating the state
+func (s *RateLimitState) Take(limit RateLimit, now time.Time) RateLimitResult {
+	if limit.Algorithm == SlidingWindow {
+		return s.takeSlidingWindow(limit, now)
+	}
+	return s.takeTokenBucket(limit, now)
+}
+
+// ExpiresAt returns when the state is equivalent to a fresh one and can be
+// discarded
+func (s *RateLimitState) ExpiresAt(limit RateLimit) time.Time {
+	if limit.Algorithm == SlidingWindow {
+		return s.WindowStart.Add(2 * limit.Window)
+	}
+
+	// The bucket is full again once the missing tokens have refilled
+	capacity := bucketCapacity(limit)
+	perSecond := float64(limit.Requests) / limit.Window.Seconds()
+	return s.Updated.Add(secondsToDuration((capacity - s.Tokens) / perSecond))
+}
+
+func (s *RateLimitState) takeTokenBucket(limit RateLimit, now time.Time) RateLimitResult {
+	capacity := bucketCapacity(limit)
+	perSecond := float64(limit.Requests) / limit.Window.Seconds()
+
+	if s.Updated.IsZero() {
+		s.Tokens = capacity
+	} else if elapsed := now.Sub(s.Updated).Seconds(); elapsed > 0 {
+		s.Tokens = math.Min(capacity, s.Tokens+elapsed*perSecond)
+	}
+	s.Updated = now
+
+	result := RateLimitResult{Limit: int(capacity)}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - s.Tokens) / perSecond)
+	}
+
+	result.Remaining = int(s.Tokens)
+	result.Reset = secondsToDuration((capacity - s.Tokens) / perSecond)
+	return result
+}
+
+func (s *RateLimitState) takeSlidingWindow(limit RateLimit, now time.Time) RateLimitResult {
+	window := limit.Window
+	start := now.Truncate(window)
+
+	switch {
+	case s.WindowStart.Equal(start):
+	case s.WindowStart.Add(window).Equal(start):
+		s.PrevCount, s.Count = s.Count, 0
+	default:
+		s.PrevCount, s.Count = 0, 0
+	}
+	s.WindowStart = start
+
+	// Weight the previous window by how much of it still overlaps
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(window)
+	estimate := float64(s.PrevCount)*weight + float64(s.Count)
+
+	result := RateLimitResult{Limit: limit.Requests, Reset: start.Add(window).Sub(now)}
+	if estimate+1 <= float64(limit.Requests) {
+		s.Count++
+		result.Allowed = true
+		estimate++
+	} else if s.Count >= limit.Requests || s.PrevCount == 0 {
+		// Not enough room until the current window rolls over
+		result.RetryAfter = result.Reset
+	} else {
+		// Wait for enough of the previous window to slide out
+		needed := 1 - (float64(limit.Requests)-1-float64(s.Count))/float64(s.PrevCount)
+		result.RetryAfter = time.Duration(needed*float64(window)) - elapsed
+		if result.RetryAfter < 0 {
+			result.RetryAfter = 0
+		}
+	}
+
+	result.Remaining = max(0, limit.Requests-int(math.Ceil(estimate)))
+	// Requests in the current window weigh on the next one too
+	if s.Count > 0 {
+		result.Reset += window
+	}
+	return result
+}
+
+// bucketCapacity returns the token bucket size
+func bucketCapacity(limit RateLimit) float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+	return float64(limit.Requests)
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// MemoryRateLimitStoreConfig holds configuration for the in-memory store
+type MemoryRateLimitStoreConfig struct {
+	MaxKeys int // Least recently used keys are evicted beyond this (default: 100000)
+}
+
+// DefaultMemoryRateLimitStoreConfig returns default in-memory store
+// configuration
+func DefaultMemoryRateLimitStoreConfig() MemoryRateLimitStoreConfig {
+	return MemoryRateLimitStoreConfig{MaxKeys: 100000}
+}
+
+// memoryRateLimitEntry is a key's state in the LRU list
+type memoryRateLimitEntry struct {
+	key       string
+	state     RateLimitState
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore keeps rate limit state in process. Limits are per
+// replica; idle keys expire and the least recently used keys are evicted
+// once MaxKeys is reached.
+type MemoryRateLimitStore struct {
+	config MemoryRateLimitStoreConfig
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	lru       *list.List // Front is most recently used
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory rate limit store
+func NewMemoryRateLimitStore(config MemoryRateLimitStoreConfig) *MemoryRateLimitStore {
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultMemoryRateLimitStoreConfig().MaxKeys
+	}
+
+	return &MemoryRateLimitStore{
+		config:    config,
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take implements RateLimitStore
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	var entry *memoryRateLimitEntry
+	if element, exists := s.entries[key]; exists {
+		entry = element.Value.(*memoryRateLimitEntry)
+		s.lru.MoveToFront(element)
+	} else {
+		entry = &memoryRateLimitEntry{key: key}
+		s.entries[key] = s.lru.PushFront(entry)
+
+		for s.lru.Len() > s.config.MaxKeys {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.entries, oldest.Value.(*memoryRateLimitEntry).key)
+		}
+	}
+
+	result := entry.state.Take(limit, now)
+	entry.expiresAt = entry.state.ExpiresAt(limit)
+	return result, nil
+}
+
+// Len returns the number of keys held
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// sweepLocked drops expired keys at most once a minute. Callers hold mu.
+func (s *MemoryRateLimitStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, element := range s.entries {
+		if now.After(element.Value.(*memoryRateLimitEntry).expiresAt) {
+			s.lru.Remove(element)
+			delete(s.entries, key)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"runtime/debug"
 
+	"github.com/JorgeSaicoski/microservice-commons/logging"
 	"github.com/gin-gonic/gin"
 )
 
@@ -72,7 +74,7 @@ func ProductionRecoveryMiddleware() gin.HandlerFunc {
 			requestID := MustGetRequestID(c)
 
 			// Log error without stack trace
-			fmt.Printf("[PANIC RECOVERY] Request ID: %s, Error: %v\n", requestID, recovered)
+			logPanic(recovered, false, requestID, c)
 
 			// Return minimal error response
 			errorResponse := gin.H{
@@ -149,13 +151,18 @@ func CustomErrorRecoveryMiddleware(errorHandler func(c *gin.Context, err interfa
 
 // logPanic logs panic information
 func logPanic(recovered interface{}, enableStackTrace bool, requestID string, c *gin.Context) {
-	fmt.Printf("[PANIC RECOVERY] Request ID: %s\n", requestID)
-	fmt.Printf("[PANIC RECOVERY] URL: %s %s\n", c.Request.Method, c.Request.URL.Path)
-	fmt.Printf("[PANIC RECOVERY] Error: %v\n", recovered)
+	attrs := []any{
+		slog.String("request_id", requestID),
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.Request.URL.Path),
+		slog.String("error", fmt.Sprintf("%v", recovered)),
+	}
 
 	if enableStackTrace {
-		fmt.Printf("[PANIC RECOVERY] Stack trace:\n%s\n", debug.Stack())
+		attrs = append(attrs, slog.String("stack", string(debug.Stack())))
 	}
+
+	logging.Default().Error("panic recovered", attrs...)
 }
 
 // PanicHandler is a type for custom panic handling functions
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,9 +37,23 @@ func DefaultRequestIDConfig() RequestIDConfig {
 // NewRequestIDMiddleware creates a new request ID middleware
 func NewRequestIDMiddleware(config RequestIDConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Reuse an ID already assigned by an earlier request ID middleware
+		if existing, exists := c.Get(config.ContextKey); exists {
+			if id, ok := existing.(string); ok && id != "" {
+				c.Next()
+				return
+			}
+		}
+
 		// Check if request ID already exists in header
 		requestID := c.GetHeader(config.HeaderName)
 
+		// Reuse the trace ID when the request is traced, so logs and
+		// traces share one identifier
+		if requestID == "" {
+			requestID = tracing.TraceIDFromContext(c.Request.Context())
+		}
+
 		// Generate new ID if not present
 		if requestID == "" {
 			requestID = config.Generator()
@@ -45,6 +61,7 @@ func NewRequestIDMiddleware(config RequestIDConfig) gin.HandlerFunc {
 
 		// Set request ID in context
 		c.Set(config.ContextKey, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
 
 		// Set request ID in response header
 		c.Header(config.HeaderName, requestID)
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+// RevocationChecker reports whether a validated token has been revoked,
+// either by its ID (jti) or because its subject's tokens issued before a
+// cutoff were revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, tokenID, subject string, issuedAt time.Time) (bool, error)
+}
+
+// ErrRevokedToken is returned for tokens that were revoked before expiry
+var ErrRevokedToken = &AuthError{
+	Code:    "revoked_token",
+	Message: "Authorization token has been revoked",
+}
+
+// checkRevocation runs the checker against the token's claims
+func checkRevocation(ctx context.Context, checker RevocationChecker, claims map[string]interface{}) error {
+	tokenID, _ := claims["jti"].(string)
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		subject, _ = claims["user_id"].(string)
+	}
+
+	revoked, err := checker.IsRevoked(ctx, tokenID, subject, claimTime(claims["iat"]))
+	if err != nil {
+		// Fail closed: a token that cannot be checked is not trusted
+		logging.FromContext(ctx).Warn("token revocation check failed", logging.Err(err))
+		return ErrInvalidToken
+	}
+	if revoked {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// claimTime converts a numeric date claim to a time, returning the zero
+// time if it is missing
+func claimTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case float64:
+		return time.Unix(int64(v), 0)
+	case int64:
+		return time.Unix(v, 0)
+	case int:
+		return time.Unix(int64(v), 0)
+	case json.Number:
+		if seconds, err := v.Int64(); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,353 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSPNonceKey is the context key for the request's CSP nonce
+const CSPNonceKey = "csp_nonce"
+
+// SecurityHeadersConfig holds configuration for security headers middleware.
+// Empty values leave the corresponding header unset.
+type SecurityHeadersConfig struct {
+	HSTSMaxAge            time.Duration // Strict-Transport-Security max-age (0 disables)
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	ContentTypeNosniff        bool   // X-Content-Type-Options: nosniff
+	FrameOptions              string // X-Frame-Options: DENY or SAMEORIGIN
+	ReferrerPolicy            string
+	PermissionsPolicy         string
+	CrossOriginOpenerPolicy   string
+	CrossOriginEmbedderPolicy string
+
+	ContentSecurityPolicy *CSPBuilder
+	CSPReportOnly         bool // Send Content-Security-Policy-Report-Only instead
+
+	SkipPaths []string
+}
+
+// DefaultSecurityHeadersConfig returns headers suited to JSON APIs, which
+// never need to load or frame content
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:              365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:   true,
+		ContentTypeNosniff:      true,
+		FrameOptions:            "DENY",
+		ReferrerPolicy:          "strict-origin-when-cross-origin",
+		PermissionsPolicy:       "camera=(), microphone=(), geolocation=()",
+		CrossOriginOpenerPolicy: "same-origin",
+		ContentSecurityPolicy: NewCSP().
+			DefaultSrc(CSPNone).
+			FrameAncestors(CSPNone),
+	}
+}
+
+// DevelopmentSecurityHeadersConfig returns permissive headers for local
+// development: no HSTS, and a report-only policy that allows inline code
+// and dev server websockets
+func DevelopmentSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		ContentTypeNosniff: true,
+		FrameOptions:       "SAMEORIGIN",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: NewCSP().
+			DefaultSrc(CSPSelf).
+			ScriptSrc(CSPSelf, CSPUnsafeInline, CSPUnsafeEval).
+			StyleSrc(CSPSelf, CSPUnsafeInline).
+			ImgSrc(CSPSelf, CSPData, CSPBlob).
+			ConnectSrc(CSPSelf, "ws:", "wss:"),
+		CSPReportOnly: true,
+	}
+}
+
+// ProductionSecurityHeadersConfig returns strict headers for production.
+// Scripts must carry the request's nonce; see GetCSPNonce.
+func ProductionSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:                2 * 365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		HSTSPreload:               true,
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		PermissionsPolicy:         "camera=(), microphone=(), geolocation=(), payment=()",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginEmbedderPolicy: "require-corp",
+		ContentSecurityPolicy: NewCSP().
+			DefaultSrc(CSPSelf).
+			ScriptSrc(CSPNonce, CSPStrictDynamic).
+			StyleSrc(CSPSelf, CSPNonce).
+			ObjectSrc(CSPNone).
+			BaseURI(CSPNone).
+			FrameAncestors(CSPNone).
+			UpgradeInsecureRequests(),
+	}
+}
+
+// NewSecurityHeadersMiddleware creates a middleware that sets security
+// headers on every response
+func NewSecurityHeadersMiddleware(config SecurityHeadersConfig) gin.HandlerFunc {
+	// Everything but the nonce is the same for every request
+	headers := make(map[string]string)
+	if config.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(config.HSTSMaxAge/time.Second), 10)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	if config.ContentTypeNosniff {
+		headers["X-Content-Type-Options"] = "nosniff"
+	}
+	setIfNotEmpty(headers, "X-Frame-Options", config.FrameOptions)
+	setIfNotEmpty(headers, "Referrer-Policy", config.ReferrerPolicy)
+	setIfNotEmpty(headers, "Permissions-Policy", config.PermissionsPolicy)
+	setIfNotEmpty(headers, "Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy)
+	setIfNotEmpty(headers, "Cross-Origin-Embedder-Policy", config.CrossOriginEmbedderPolicy)
+
+	cspHeader := "Content-Security-Policy"
+	if config.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	var policy string
+	usesNonce := false
+	if config.ContentSecurityPolicy != nil {
+		policy = config.ContentSecurityPolicy.String()
+		usesNonce = config.ContentSecurityPolicy.UsesNonce()
+	}
+
+	return func(c *gin.Context) {
+		if shouldSkipAuth(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		for name, value := range headers {
+			header.Set(name, value)
+		}
+
+		if policy != "" {
+			if usesNonce {
+				nonce := generateCSPNonce()
+				c.Set(CSPNonceKey, nonce)
+				header.Set(cspHeader, strings.ReplaceAll(policy, string(CSPNonce), "'nonce-"+nonce+"'"))
+			} else {
+				header.Set(cspHeader, policy)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// DefaultSecurityHeadersMiddleware creates a security headers middleware
+// with default configuration
+func DefaultSecurityHeadersMiddleware() gin.HandlerFunc {
+	return NewSecurityHeadersMiddleware(DefaultSecurityHeadersConfig())
+}
+
+// DevelopmentSecurityHeadersMiddleware creates a permissive security headers
+// middleware for development
+func DevelopmentSecurityHeadersMiddleware() gin.HandlerFunc {
+	return NewSecurityHeadersMiddleware(DevelopmentSecurityHeadersConfig())
+}
+
+// ProductionSecurityHeadersMiddleware creates a strict security headers
+// middleware for production
+func ProductionSecurityHeadersMiddleware() gin.HandlerFunc {
+	return NewSecurityHeadersMiddleware(ProductionSecurityHeadersConfig())
+}
+
+// GetCSPNonce returns the request's CSP nonce, for use in script and style
+// tags as nonce="..."
+func GetCSPNonce(c *gin.Context) string {
+	return c.GetString(CSPNonceKey)
+}
+
+// generateCSPNonce returns a random base64 nonce
+func generateCSPNonce() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func setIfNotEmpty(headers map[string]string, name, value string) {
+	if value != "" {
+		headers[name] = value
+	}
+}
+
+// CSPSource is a Content-Security-Policy source expression, such as a
+// keyword, scheme or host
+type CSPSource string
+
+// Common CSP sources
+const (
+	CSPSelf          CSPSource = "'self'"
+	CSPNone          CSPSource = "'none'"
+	CSPUnsafeInline  CSPSource = "'unsafe-inline'"
+	CSPUnsafeEval    CSPSource = "'unsafe-eval'"
+	CSPStrictDynamic CSPSource = "'strict-dynamic'"
+	CSPReportSample  CSPSource = "'report-sample'"
+	CSPData          CSPSource = "data:"
+	CSPBlob          CSPSource = "blob:"
+	CSPHTTPS         CSPSource = "https:"
+	CSPNonce         CSPSource = "'nonce'" // Replaced by the request's nonce
+)
+
+// cspDirective is a directive and its sources
+type cspDirective struct {
+	name    string
+	sources []CSPSource
+}
+
+// CSPBuilder builds a Content-Security-Policy header value. Directives are
+// written in the order they are first set; setting one again replaces it.
+type CSPBuilder struct {
+	directives []cspDirective
+}
+
+// NewCSP creates an empty CSP builder
+func NewCSP() *CSPBuilder {
+	return &CSPBuilder{}
+}
+
+// Directive sets any directive
+func (b *CSPBuilder) Directive(name string, sources ...CSPSource) *CSPBuilder {
+	for i := range b.directives {
+		if b.directives[i].name == name {
+			b.directives[i].sources = sources
+			return b
+		}
+	}
+	b.directives = append(b.directives, cspDirective{name: name, sources: sources})
+	return b
+}
+
+// DefaultSrc sets default-src
+func (b *CSPBuilder) DefaultSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("default-src", sources...)
+}
+
+// ScriptSrc sets script-src
+func (b *CSPBuilder) ScriptSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("script-src", sources...)
+}
+
+// StyleSrc sets style-src
+func (b *CSPBuilder) StyleSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("style-src", sources...)
+}
+
+// ImgSrc sets img-src
+func (b *CSPBuilder) ImgSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("img-src", sources...)
+}
+
+// ConnectSrc sets connect-src
+func (b *CSPBuilder) ConnectSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("connect-src", sources...)
+}
+
+// FontSrc sets font-src
+func (b *CSPBuilder) FontSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("font-src", sources...)
+}
+
+// ObjectSrc sets object-src
+func (b *CSPBuilder) ObjectSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("object-src", sources...)
+}
+
+// MediaSrc sets media-src
+func (b *CSPBuilder) MediaSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("media-src", sources...)
+}
+
+// FrameSrc sets frame-src
+func (b *CSPBuilder) FrameSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("frame-src", sources...)
+}
+
+// WorkerSrc sets worker-src
+func (b *CSPBuilder) WorkerSrc(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("worker-src", sources...)
+}
+
+// FrameAncestors sets frame-ancestors
+func (b *CSPBuilder) FrameAncestors(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("frame-ancestors", sources...)
+}
+
+// BaseURI sets base-uri
+func (b *CSPBuilder) BaseURI(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("base-uri", sources...)
+}
+
+// FormAction sets form-action
+func (b *CSPBuilder) FormAction(sources ...CSPSource) *CSPBuilder {
+	return b.Directive("form-action", sources...)
+}
+
+// UpgradeInsecureRequests sets upgrade-insecure-requests
+func (b *CSPBuilder) UpgradeInsecureRequests() *CSPBuilder {
+	return b.Directive("upgrade-insecure-requests")
+}
+
+// ReportURI sets report-uri
+func (b *CSPBuilder) ReportURI(uri string) *CSPBuilder {
+	return b.Directive("report-uri", CSPSource(uri))
+}
+
+// ReportTo sets report-to, naming a Reporting-Endpoints group
+func (b *CSPBuilder) ReportTo(group string) *CSPBuilder {
+	return b.Directive("report-to", CSPSource(group))
+}
+
+// UsesNonce reports whether any directive uses CSPNonce
+func (b *CSPBuilder) UsesNonce() bool {
+	for _, directive := range b.directives {
+		for _, source := range directive.sources {
+			if source == CSPNonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Build returns the policy with CSPNonce replaced by nonce
+func (b *CSPBuilder) Build(nonce string) string {
+	parts := make([]string, 0, len(b.directives))
+	for _, directive := range b.directives {
+		var part strings.Builder
+		part.WriteString(directive.name)
+		for _, source := range directive.sources {
+			part.WriteByte(' ')
+			if source == CSPNonce && nonce != "" {
+				part.WriteString("'nonce-" + nonce + "'")
+				continue
+			}
+			part.WriteString(string(source))
+		}
+		parts = append(parts, part.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// String returns the policy with CSPNonce placeholders left in place
+func (b *CSPBuilder) String() string {
+	return b.Build("")
+}
@@ -0,0 +1,311 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutConfig holds configuration for timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration // Deadline for each request (default: 10s)
+
+	// Routes overrides the timeout for route templates, given as
+	// "/reports/:id" or "GET /reports/:id". A zero timeout disables it.
+	Routes map[string]time.Duration
+
+	StatusCode int    // 504 or 503 (default: 504)
+	Message    string // Error message (default: "Request timed out")
+	SkipPaths  []string
+}
+
+// DefaultTimeoutConfig returns a 10 second timeout answered with 504
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		Timeout:    10 * time.Second,
+		StatusCode: http.StatusGatewayTimeout,
+		Message:    "Request timed out",
+	}
+}
+
+// NewTimeoutMiddleware creates a middleware that sets a deadline on the
+// request context. Handlers and database calls using c.Request.Context()
+// are cancelled at the deadline. If the handler has not started writing by
+// then, an error response is sent immediately and anything the handler
+// writes later is discarded, so the configured status always wins.
+//
+// Handlers run on a separate goroutine; the middleware still waits for them
+// to return before the request completes.
+func NewTimeoutMiddleware(config TimeoutConfig) gin.HandlerFunc {
+	defaults := DefaultTimeoutConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = defaults.StatusCode
+	}
+	if config.Message == "" {
+		config.Message = defaults.Message
+	}
+
+	code := responses.ErrCodeGatewayTimeout
+	if config.StatusCode == http.StatusServiceUnavailable {
+		code = responses.ErrCodeServiceUnavailable
+	}
+
+	return func(c *gin.Context) {
+		if shouldSkipAuth(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		timeout := config.timeoutFor(c)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// Everything the timeout response needs is read before the handler
+		// goroutine starts, so it never touches c concurrently
+		response := responses.ErrorResponse{
+			Error: config.Message,
+			Code:  code,
+			Path:  c.Request.URL.Path,
+		}
+		response.RequestID, _ = GetRequestID(c)
+		logger := Logger(c)
+
+		original := c.Writer
+		writer := newTimeoutWriter(ctx, original)
+		c.Writer = writer
+
+		// Answer with the timeout response once the deadline has passed,
+		// unless the handler started its response in time
+		sendTimeout := func() {
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return
+			}
+			response.Timestamp = time.Now().UTC()
+			if writer.timeout(config.StatusCode, response) {
+				logger.Warn("request timed out",
+					slog.Duration("timeout", timeout),
+				)
+			}
+		}
+
+		done := make(chan struct{})
+		var recovered interface{}
+		go func() {
+			defer func() {
+				recovered = recover()
+				close(done)
+			}()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			sendTimeout()
+			<-done
+		}
+
+		c.Writer = original
+
+		// Let recovery middleware further up handle handler panics
+		if recovered != nil {
+			panic(recovered)
+		}
+
+		// The handler may have returned after the deadline, its writes
+		// refused, before the timeout response was sent
+		sendTimeout()
+		writer.finish()
+	}
+}
+
+// TimeoutMiddleware bounds every request to timeout
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	config := DefaultTimeoutConfig()
+	config.Timeout = timeout
+	return NewTimeoutMiddleware(config)
+}
+
+// timeoutFor returns the timeout that applies to the request
+func (config *TimeoutConfig) timeoutFor(c *gin.Context) time.Duration {
+	route := c.FullPath()
+	if route != "" && len(config.Routes) > 0 {
+		if timeout, ok := config.Routes[c.Request.Method+" "+route]; ok {
+			return timeout
+		}
+		if timeout, ok := config.Routes[route]; ok {
+			return timeout
+		}
+	}
+	return config.Timeout
+}
+
+// timeoutWriter lets a handler and the timeout race for the response. The
+// handler gets its own header map, copied to the connection on its first
+// write, so a timeout before then can answer with a clean response. Once
+// the deadline has passed the handler can no longer start its response,
+// even if the timeout response has not been sent yet.
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	ctx       context.Context
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	committed bool // The handler's response has started
+	timedOut  bool // The timeout response was sent
+}
+
+func newTimeoutWriter(ctx context.Context, w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		ctx:            ctx,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+// timeout sends the error response unless the handler already started
+// writing, and reports whether it did
+func (w *timeoutWriter) timeout(status int, response responses.ErrorResponse) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.committed || w.timedOut {
+		return false
+	}
+	w.timedOut = true
+
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(status)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(response)
+	w.ResponseWriter.Flush()
+	return true
+}
+
+// finish writes the handler's status if it returned without writing a body
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commitLocked()
+}
+
+// commitLocked copies the handler's headers and status to the connection,
+// and reports whether the handler's response was started. It refuses after
+// a timeout or once the deadline has passed. Callers hold mu.
+func (w *timeoutWriter) commitLocked() bool {
+	if w.committed {
+		return true
+	}
+	if w.timedOut || errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	w.committed = true
+
+	header := w.ResponseWriter.Header()
+	for key := range header {
+		delete(header, key)
+	}
+	for key, values := range w.header {
+		header[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	return true
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.committed && !w.timedOut && code > 0 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commitLocked()
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.commitLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.commitLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed || w.timedOut {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed || w.timedOut {
+		return w.ResponseWriter.Size()
+	}
+	return -1
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.committed || w.timedOut
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.commitLocked() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.committed && (w.timedOut || errors.Is(w.ctx.Err(), context.DeadlineExceeded)) {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	w.committed = true
+	return w.ResponseWriter.Hijack()
+}
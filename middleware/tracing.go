@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// TracingConfig holds configuration for tracing middleware
+type TracingConfig struct {
+	Tracer    *tracing.Tracer // Tracer creating server spans (default: tracing.Default())
+	SkipPaths []string        // Paths that are not traced
+}
+
+// DefaultTracingConfig returns default tracing configuration
+func DefaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		SkipPaths: []string{"/health", "/ready", "/live", "/metrics"},
+	}
+}
+
+// NewTracingMiddleware creates a middleware that continues the caller's
+// W3C trace (or starts a new one) with a server span named after the route
+// template. The span is available from the request context. It should run
+// before the request ID middleware so the trace ID can be reused.
+func NewTracingMiddleware(config TracingConfig) gin.HandlerFunc {
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		tracer := config.Tracer
+		if tracer == nil {
+			tracer = tracing.Default()
+		}
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := tracing.ExtractContext(c.Request.Context(), c.Request.Header)
+		ctx, span := tracer.Start(ctx, name, tracing.SpanKindServer)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("url.path", c.Request.URL.Path)
+		span.SetAttribute("client.address", c.ClientIP())
+		if route != "" {
+			span.SetAttribute("http.route", route)
+		}
+		if userAgent := c.Request.UserAgent(); userAgent != "" {
+			span.SetAttribute("user_agent.original", userAgent)
+		}
+
+		// Let callers correlate the response with the trace
+		tracing.Inject(span.SpanContext(), c.Writer.Header())
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+
+		if requestID := MustGetRequestID(c); requestID != "" {
+			span.SetAttribute("request_id", requestID)
+		}
+		if userID, ok := GetUserID(c); ok {
+			span.SetAttribute("user_id", userID)
+		}
+
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+	}
+}
+
+// TracingMiddleware creates a tracing middleware with default configuration
+func TracingMiddleware() gin.HandlerFunc {
+	return NewTracingMiddleware(DefaultTracingConfig())
+}
+
+// GetSpan returns the current span of the request, or nil if the request
+// is not traced
+func GetSpan(c *gin.Context) *tracing.Span {
+	return tracing.SpanFromContext(c.Request.Context())
+}
@@ -0,0 +1,386 @@
+// resilience/breaker.go
+package resilience
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets calls through and records their outcome
+	StateClosed State = iota
+	// StateOpen rejects calls until OpenTimeout has passed
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through
+	StateHalfOpen
+)
+
+// String returns the state name
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChange describes a circuit breaker transition
+type StateChange struct {
+	Name string
+	From State
+	To   State
+	At   time.Time
+}
+
+// BreakerConfig holds circuit breaker configuration
+type BreakerConfig struct {
+	Name string
+
+	// Sliding window of the most recent calls used to compute rates
+	WindowSize   int // Calls kept in the window (default: 20)
+	MinimumCalls int // Calls required before rates are evaluated (default: 10)
+
+	FailureRateThreshold  float64       // Opens when the failure rate reaches this (0-1, default: 0.5)
+	SlowCallDuration      time.Duration // Calls slower than this count as slow (0 disables)
+	SlowCallRateThreshold float64       // Opens when the slow call rate reaches this (0-1, default: 0.8)
+
+	OpenTimeout      time.Duration // Time spent open before trial calls are allowed (default: 30s)
+	HalfOpenMaxCalls int           // Trial calls that must succeed to close again (default: 3)
+
+	// IsFailure classifies call errors (default: any non-nil error)
+	IsFailure func(error) bool
+
+	// OnStateChange is called after every transition
+	OnStateChange func(StateChange)
+}
+
+// DefaultBreakerConfig returns default circuit breaker configuration
+func DefaultBreakerConfig(name string) BreakerConfig {
+	return BreakerConfig{
+		Name:                  name,
+		WindowSize:            20,
+		MinimumCalls:          10,
+		FailureRateThreshold:  0.5,
+		SlowCallDuration:      5 * time.Second,
+		SlowCallRateThreshold: 0.8,
+		OpenTimeout:           30 * time.Second,
+		HalfOpenMaxCalls:      3,
+	}
+}
+
+// BreakerStats is a snapshot of a circuit breaker
+type BreakerStats struct {
+	Name         string  `json:"name"`
+	State        string  `json:"state"`
+	Calls        int     `json:"calls"`
+	FailureRate  float64 `json:"failure_rate"`
+	SlowCallRate float64 `json:"slow_call_rate"`
+}
+
+// outcome is a single recorded call
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+// CircuitBreaker stops calling a dependency once too many recent calls
+// have failed or been slow, giving it time to recover
+type CircuitBreaker struct {
+	config BreakerConfig
+
+	mu               sync.Mutex
+	state            State
+	openedAt         time.Time
+	window           []outcome
+	next             int
+	count            int
+	halfOpenInFlight int
+	halfOpenSuccess  int
+	generation       uint64
+
+	// State changes waiting to be delivered to OnStateChange, in order
+	events      []StateChange
+	dispatching bool
+}
+
+// NewCircuitBreaker creates a circuit breaker, using defaults for zero values
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	defaults := DefaultBreakerConfig(config.Name)
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaults.WindowSize
+	}
+	if config.MinimumCalls <= 0 {
+		config.MinimumCalls = defaults.MinimumCalls
+	}
+	if config.MinimumCalls > config.WindowSize {
+		config.MinimumCalls = config.WindowSize
+	}
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = defaults.FailureRateThreshold
+	}
+	if config.SlowCallRateThreshold <= 0 {
+		config.SlowCallRateThreshold = defaults.SlowCallRateThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = defaults.HalfOpenMaxCalls
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		window: make([]outcome, config.WindowSize),
+	}
+}
+
+// Name returns the breaker name
+func (cb *CircuitBreaker) Name() string {
+	return cb.config.Name
+}
+
+// Execute runs fn if the circuit allows it and records the outcome.
+// It returns ErrCircuitOpen without calling fn when the circuit is open.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = fn(ctx)
+	done(cb.config.IsFailure(err), time.Since(start))
+
+	return err
+}
+
+// Allow reserves a call. The returned function must be called with
+// whether the call failed and how long it took once it completes.
+func (cb *CircuitBreaker) Allow() (func(failed bool, duration time.Duration), error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.transition(StateHalfOpen, now)
+	}
+
+	switch cb.state {
+	case StateOpen:
+		return nil, &RejectedError{Name: cb.config.Name, Reason: ErrCircuitOpen}
+	case StateHalfOpen:
+		if cb.halfOpenInFlight+cb.halfOpenSuccess >= cb.config.HalfOpenMaxCalls {
+			return nil, &RejectedError{Name: cb.config.Name, Reason: ErrCircuitOpen}
+		}
+		cb.halfOpenInFlight++
+	}
+
+	generation := cb.generation
+	var once sync.Once
+
+	return func(failed bool, duration time.Duration) {
+		once.Do(func() {
+			cb.record(generation, outcome{
+				failed: failed,
+				slow:   cb.config.SlowCallDuration > 0 && duration > cb.config.SlowCallDuration,
+			})
+		})
+	}, nil
+}
+
+// State returns the current state
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= cb.config.OpenTimeout {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// Stats returns a snapshot of the breaker
+func (cb *CircuitBreaker) Stats() BreakerStats {
+	state := cb.State()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failureRate, slowRate := cb.rates()
+	return BreakerStats{
+		Name:         cb.config.Name,
+		State:        state.String(),
+		Calls:        cb.count,
+		FailureRate:  failureRate,
+		SlowCallRate: slowRate,
+	}
+}
+
+// Reset closes the circuit and clears recorded calls
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.transition(StateClosed, time.Now())
+}
+
+// HealthChecker reports the breaker as degraded while it is not closed
+func (cb *CircuitBreaker) HealthChecker() middleware.HealthChecker {
+	return func() middleware.HealthCheck {
+		stats := cb.Stats()
+
+		check := middleware.HealthCheck{
+			Name:   cb.config.Name,
+			Status: middleware.HealthStatusHealthy,
+			Metadata: map[string]interface{}{
+				"state":          stats.State,
+				"calls":          stats.Calls,
+				"failure_rate":   stats.FailureRate,
+				"slow_call_rate": stats.SlowCallRate,
+			},
+		}
+
+		if stats.State != StateClosed.String() {
+			check.Status = middleware.HealthStatusDegraded
+			check.Message = "Circuit breaker is " + stats.State
+		}
+
+		return check
+	}
+}
+
+// RegisterHealthCheck adds the breaker's health checker to config
+func (cb *CircuitBreaker) RegisterHealthCheck(config *middleware.HealthConfig) {
+	config.AddHealthChecker("circuit_breaker:"+cb.config.Name, cb.HealthChecker())
+}
+
+// record adds an outcome and evaluates the thresholds
+func (cb *CircuitBreaker) record(generation uint64, result outcome) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// Ignore results of calls admitted before the last transition
+	if generation != cb.generation {
+		return
+	}
+
+	now := time.Now()
+
+	if cb.state == StateHalfOpen {
+		cb.halfOpenInFlight--
+		if result.failed || result.slow {
+			cb.transition(StateOpen, now)
+			return
+		}
+		cb.halfOpenSuccess++
+		if cb.halfOpenSuccess >= cb.config.HalfOpenMaxCalls {
+			cb.transition(StateClosed, now)
+		}
+		return
+	}
+
+	cb.window[cb.next] = result
+	cb.next = (cb.next + 1) % len(cb.window)
+	if cb.count < len(cb.window) {
+		cb.count++
+	}
+
+	if cb.count < cb.config.MinimumCalls {
+		return
+	}
+
+	failureRate, slowRate := cb.rates()
+	if failureRate >= cb.config.FailureRateThreshold ||
+		(cb.config.SlowCallDuration > 0 && slowRate >= cb.config.SlowCallRateThreshold) {
+		cb.transition(StateOpen, now)
+	}
+}
+
+// rates returns the failure and slow call rates of the window
+func (cb *CircuitBreaker) rates() (float64, float64) {
+	if cb.count == 0 {
+		return 0, 0
+	}
+
+	var failed, slow int
+	for i := 0; i < cb.count; i++ {
+		if cb.window[i].failed {
+			failed++
+		}
+		if cb.window[i].slow {
+			slow++
+		}
+	}
+
+	return float64(failed) / float64(cb.count), float64(slow) / float64(cb.count)
+}
+
+// transition moves to a new state, resetting counters. Callers hold mu.
+func (cb *CircuitBreaker) transition(to State, now time.Time) {
+	from := cb.state
+	cb.state = to
+	cb.generation++
+	cb.halfOpenInFlight = 0
+	cb.halfOpenSuccess = 0
+
+	switch to {
+	case StateOpen:
+		cb.openedAt = now
+	case StateClosed:
+		cb.next = 0
+		cb.count = 0
+	}
+
+	if from == to {
+		return
+	}
+
+	change := StateChange{Name: cb.config.Name, From: from, To: to, At: now}
+
+	logging.Default().Info("circuit breaker state changed",
+		slog.String("breaker", cb.config.Name),
+		slog.String("from", from.String()),
+		slog.String("to", to.String()),
+	)
+
+	if cb.config.OnStateChange != nil {
+		cb.events = append(cb.events, change)
+		if !cb.dispatching {
+			cb.dispatching = true
+			go cb.dispatch()
+		}
+	}
+}
+
+// dispatch delivers queued state changes in order, outside the lock so
+// callbacks may use the breaker
+func (cb *CircuitBreaker) dispatch() {
+	for {
+		cb.mu.Lock()
+		if len(cb.events) == 0 {
+			cb.dispatching = false
+			cb.mu.Unlock()
+			return
+		}
+		change := cb.events[0]
+		cb.events = cb.events[1:]
+		cb.mu.Unlock()
+
+		cb.config.OnStateChange(change)
+	}
+}
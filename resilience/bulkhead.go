@@ -0,0 +1,133 @@
+// resilience/bulkhead.go
+package resilience
+
+import (
+	"context"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+)
+
+// BulkheadConfig holds bulkhead configuration
+type BulkheadConfig struct {
+	Name          string
+	MaxConcurrent int           // Calls allowed at once (default: 10)
+	MaxWait       time.Duration // Time to wait for a free slot (0 = reject immediately)
+}
+
+// DefaultBulkheadConfig returns default bulkhead configuration
+func DefaultBulkheadConfig(name string) BulkheadConfig {
+	return BulkheadConfig{
+		Name:          name,
+		MaxConcurrent: 10,
+	}
+}
+
+// BulkheadStats is a snapshot of a bulkhead
+type BulkheadStats struct {
+	Name          string `json:"name"`
+	InFlight      int    `json:"in_flight"`
+	MaxConcurrent int    `json:"max_concurrent"`
+}
+
+// Bulkhead limits the number of concurrent calls to a dependency so a
+// slow dependency cannot exhaust the service's resources
+type Bulkhead struct {
+	config BulkheadConfig
+	slots  chan struct{}
+}
+
+// NewBulkhead creates a bulkhead, using defaults for zero values
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultBulkheadConfig(config.Name).MaxConcurrent
+	}
+
+	return &Bulkhead{
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// Name returns the bulkhead name
+func (b *Bulkhead) Name() string {
+	return b.config.Name
+}
+
+// Execute runs fn once a slot is free, returning ErrBulkheadFull if none
+// frees up within MaxWait
+func (b *Bulkhead) Execute(ctx context.Context, fn func(context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn(ctx)
+}
+
+// Acquire reserves a slot. The returned function releases it.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	if b.config.MaxWait <= 0 {
+		return nil, &RejectedError{Name: b.config.Name, Reason: ErrBulkheadFull}
+	}
+
+	timer := time.NewTimer(b.config.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	case <-timer.C:
+		return nil, &RejectedError{Name: b.config.Name, Reason: ErrBulkheadFull}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the bulkhead
+func (b *Bulkhead) Stats() BulkheadStats {
+	return BulkheadStats{
+		Name:          b.config.Name,
+		InFlight:      len(b.slots),
+		MaxConcurrent: b.config.MaxConcurrent,
+	}
+}
+
+// HealthChecker reports the bulkhead as degraded while it is full
+func (b *Bulkhead) HealthChecker() middleware.HealthChecker {
+	return func() middleware.HealthCheck {
+		stats := b.Stats()
+
+		check := middleware.HealthCheck{
+			Name:   b.config.Name,
+			Status: middleware.HealthStatusHealthy,
+			Metadata: map[string]interface{}{
+				"in_flight":      stats.InFlight,
+				"max_concurrent": stats.MaxConcurrent,
+			},
+		}
+
+		if stats.InFlight >= stats.MaxConcurrent {
+			check.Status = middleware.HealthStatusDegraded
+			check.Message = "Bulkhead is full"
+		}
+
+		return check
+	}
+}
+
+// RegisterHealthCheck adds the bulkhead's health checker to config
+func (b *Bulkhead) RegisterHealthCheck(config *middleware.HealthConfig) {
+	config.AddHealthChecker("bulkhead:"+b.config.Name, b.HealthChecker())
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
@@ -0,0 +1,32 @@
+// resilience/errors.go
+package resilience
+
+import "errors"
+
+var (
+	// ErrCircuitOpen is returned when a circuit breaker rejects a call
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrBulkheadFull is returned when a bulkhead has no free slot
+	ErrBulkheadFull = errors.New("bulkhead is full")
+)
+
+// RejectedError is returned when a call is rejected without being made.
+// It matches ErrCircuitOpen or ErrBulkheadFull with errors.Is.
+type RejectedError struct {
+	Name   string
+	Reason error
+}
+
+func (e *RejectedError) Error() string {
+	return e.Name + ": " + e.Reason.Error()
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Reason
+}
+
+// Retryable reports false so the outbound client does not retry calls
+// that were rejected locally
+func (e *RejectedError) Retryable() bool {
+	return false
+}
@@ -0,0 +1,109 @@
+// resilience/transport.go
+package resilience
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportConfig holds configuration for a resilient round tripper
+type TransportConfig struct {
+	Next     http.RoundTripper // Wrapped transport (default: http.DefaultTransport)
+	Breaker  *CircuitBreaker   // Optional circuit breaker
+	Bulkhead *Bulkhead         // Optional bulkhead
+
+	// IsFailure classifies responses for the breaker (default: transport
+	// errors and 5xx statuses). Errors from requests whose own context was
+	// cancelled or timed out are never failures.
+	IsFailure func(*http.Response, error) bool
+}
+
+// NewTransport wraps a round tripper with a bulkhead and circuit breaker.
+// Use it as client.Config.Transport to protect an outbound client; calls
+// rejected locally are returned as *RejectedError and are not retried. A
+// bulkhead slot is held until the response body is closed.
+func NewTransport(config TransportConfig) http.RoundTripper {
+	if config.Next == nil {
+		config.Next = http.DefaultTransport
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	return &transport{config: config}
+}
+
+type transport struct {
+	config TransportConfig
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release := func() {}
+	if t.config.Bulkhead != nil {
+		acquired, err := t.config.Bulkhead.Acquire(req.Context())
+		if err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		release = acquired
+	}
+
+	resp, err := t.roundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// roundTrip sends the request through the circuit breaker, if any
+func (t *transport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.config.Breaker == nil {
+		return t.config.Next.RoundTrip(req)
+	}
+
+	done, err := t.config.Breaker.Allow()
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := t.config.Next.RoundTrip(req)
+
+	// A caller hanging up or giving up says nothing about the dependency
+	if err != nil && req.Context().Err() != nil {
+		done(false, 0)
+		return resp, err
+	}
+	done(t.config.IsFailure(resp, err), time.Since(start))
+
+	return resp, err
+}
+
+// releaseOnClose frees the bulkhead slot when the response body is closed,
+// so reading the body counts against the limit too
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// closeBody honours the RoundTripper contract of closing the request body
+// even when the request is not sent
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -1,6 +1,8 @@
 package responses
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -43,6 +45,7 @@ const (
 	ErrCodeTooManyRequests      = "too_many_requests"
 	ErrCodeInternalError        = "internal_error"
 	ErrCodeServiceUnavailable   = "service_unavailable"
+	ErrCodeGatewayTimeout       = "gateway_timeout"
 	ErrCodeValidationFailed     = "validation_failed"
 	ErrCodeDatabaseError        = "database_error"
 	ErrCodeExternalServiceError = "external_service_error"
@@ -162,6 +165,11 @@ func ServiceUnavailable(c *gin.Context, message string) {
 	Error(c, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, message)
 }
 
+// GatewayTimeout sends a 504 Gateway Timeout error
+func GatewayTimeout(c *gin.Context, message string) {
+	Error(c, http.StatusGatewayTimeout, ErrCodeGatewayTimeout, message)
+}
+
 // ValidationError sends a validation error response
 func ValidationError(c *gin.Context, message string, validationErrors interface{}) {
 	ErrorWithMetadata(c, http.StatusBadRequest, ErrCodeValidationFailed, message, validationErrors)
@@ -205,6 +213,12 @@ func HandleError(c *gin.Context, err error) {
 		return
 	}
 
+	// The request deadline passed, e.g. while waiting on the database
+	if errors.Is(err, context.DeadlineExceeded) {
+		GatewayTimeout(c, "Request timed out")
+		return
+	}
+
 	// Default to internal server error
 	InternalError(c, err.Error())
 }
@@ -282,6 +282,26 @@ func PaginatedWithLinks(c *gin.Context, data interface{}, total int64, page, pag
 	c.JSON(http.StatusOK, response)
 }
 
+// PaginatedWithRequestLinks sends a paginated response with HATEOAS links
+// built from the absolute URL of the current request path
+func PaginatedWithRequestLinks(c *gin.Context, data interface{}, total int64, page, pageSize int) {
+	PaginatedWithLinks(c, data, total, page, pageSize, AbsoluteURL(c, c.Request.URL.Path))
+}
+
+// AbsoluteURL builds an absolute URL for path from the request scheme and
+// host. Behind proxies, use the proxy headers middleware so these reflect
+// the URL the client requested.
+func AbsoluteURL(c *gin.Context, path string) string {
+	scheme := c.Request.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + c.Request.Host + path
+}
+
 // buildURL builds pagination URL
 func buildURL(baseURL string, page, pageSize int) string {
 	return baseURL + "?page=" + strconv.Itoa(page) + "&page_size=" + strconv.Itoa(pageSize)
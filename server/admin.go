@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteInfo describes a route registered on the public router
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// AdminServer serves operational endpoints on a separate port so they are
+// never exposed through the public ingress
+type AdminServer struct {
+	router *gin.Engine
+	server *http.Server
+}
+
+// newAdminServer creates the admin server for the given service server
+func newAdminServer(s *Server, port string) *AdminServer {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	admin := &AdminServer{
+		router: router,
+		server: &http.Server{
+			Addr:              ":" + port,
+			Handler:           router,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+
+	// Profiling
+	router.GET("/debug/pprof/", gin.WrapF(pprof.Index))
+	router.GET("/debug/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+	router.GET("/debug/pprof/profile", gin.WrapF(pprof.Profile))
+	router.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+	router.GET("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+	router.GET("/debug/pprof/trace", gin.WrapF(pprof.Trace))
+	router.GET("/debug/pprof/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+
+	// Runtime information
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	router.GET("/debug/goroutines", goroutineDumpHandler)
+
+	// Service information
+	router.GET("/routes", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"routes": s.Routes()})
+	})
+	router.GET("/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.config.Redacted())
+	})
+
+	// Health and metrics
+	router.GET("/health", s.healthCheckHandler)
+	router.GET("/health/detailed", s.detailedHealthHandler)
+	router.GET("/ready", s.readinessHandler)
+	router.GET("/metrics", gin.WrapH(expvar.Handler()))
+
+	// Runtime log level control
+	router.Any("/log/level", gin.WrapH(logging.LevelHandler()))
+
+	return admin
+}
+
+// goroutineDumpHandler writes the stacks of all goroutines
+func goroutineDumpHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	if err := runtimepprof.Lookup("goroutine").WriteTo(c.Writer, 2); err != nil {
+		fmt.Fprintf(c.Writer, "failed to write goroutine dump: %v\n", err)
+	}
+}
+
+// Start binds the admin listener and serves in the background
+func (a *AdminServer) Start() error {
+	ln, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return fmt.Errorf("admin server: %w", err)
+	}
+
+	go func() {
+		logging.Default().Info("starting admin server", slog.String("addr", ln.Addr().String()))
+		if err := a.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Default().Error("admin server failed", logging.Err(err))
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully shuts down the admin server
+func (a *AdminServer) Shutdown(ctx context.Context) error {
+	return a.server.Shutdown(ctx)
+}
+
+// GetRouter returns the admin router (useful for testing or adding endpoints)
+func (a *AdminServer) GetRouter() *gin.Engine {
+	return a.router
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InFlightRequest describes a request that is currently being served
+type InFlightRequest struct {
+	Method    string        `json:"method"`
+	Path      string        `json:"path"`
+	ClientIP  string        `json:"client_ip"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// RequestTracker tracks in-flight requests and whether the server is draining
+type RequestTracker struct {
+	mu       sync.Mutex
+	nextID   uint64
+	requests map[uint64]InFlightRequest
+	draining atomic.Bool
+}
+
+// NewRequestTracker creates a new in-flight request tracker
+func NewRequestTracker() *RequestTracker {
+	return &RequestTracker{
+		requests: make(map[uint64]InFlightRequest),
+	}
+}
+
+// Middleware registers every request for the duration of its handling.
+// While draining, responses ask clients to close the connection so they
+// migrate to other instances.
+func (t *RequestTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t.mu.Lock()
+		t.nextID++
+		id := t.nextID
+		t.requests[id] = InFlightRequest{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			ClientIP:  c.ClientIP(),
+			StartedAt: time.Now(),
+		}
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			delete(t.requests, id)
+			t.mu.Unlock()
+		}()
+
+		if t.IsDraining() {
+			c.Header("Connection", "close")
+		}
+
+		c.Next()
+	}
+}
+
+// Count returns the number of in-flight requests
+func (t *RequestTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.requests)
+}
+
+// Snapshot returns the in-flight requests, oldest first
+func (t *RequestTracker) Snapshot() []InFlightRequest {
+	t.mu.Lock()
+	now := time.Now()
+	snapshot := make([]InFlightRequest, 0, len(t.requests))
+	for _, req := range t.requests {
+		req.Duration = now.Sub(req.StartedAt)
+		snapshot = append(snapshot, req)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].StartedAt.Before(snapshot[j].StartedAt)
+	})
+	return snapshot
+}
+
+// StartDraining marks the server as draining
+func (t *RequestTracker) StartDraining() {
+	t.draining.Store(true)
+}
+
+// IsDraining returns true once shutdown has begun
+func (t *RequestTracker) IsDraining() bool {
+	return t.draining.Load()
+}
@@ -2,18 +2,22 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
 )
 
 // GracefulShutdownConfig holds configuration for graceful shutdown
 type GracefulShutdownConfig struct {
 	Timeout       time.Duration // Maximum time to wait for shutdown
-	SignalTimeout time.Duration // Time to wait for signal
+	SignalTimeout time.Duration // Pre-stop delay: keep serving after a signal while readiness reports 503
 }
 
 // DefaultGracefulConfig returns default graceful shutdown configuration
@@ -26,8 +30,10 @@ func DefaultGracefulConfig() GracefulShutdownConfig {
 
 // ShutdownManager handles graceful shutdown of the server
 type ShutdownManager struct {
-	server *http.Server
-	config GracefulShutdownConfig
+	server     *http.Server
+	config     GracefulShutdownConfig
+	tracker    *RequestTracker
+	onShutdown []func(context.Context) error
 }
 
 // NewShutdownManager creates a new shutdown manager
@@ -38,6 +44,18 @@ func NewShutdownManager(server *http.Server, config GracefulShutdownConfig) *Shu
 	}
 }
 
+// WithRequestTracker sets the tracker used for draining state and straggler reporting
+func (sm *ShutdownManager) WithRequestTracker(tracker *RequestTracker) *ShutdownManager {
+	sm.tracker = tracker
+	return sm
+}
+
+// RegisterOnShutdown registers a function to run after the server has shut down
+func (sm *ShutdownManager) RegisterOnShutdown(fn func(context.Context) error) *ShutdownManager {
+	sm.onShutdown = append(sm.onShutdown, fn)
+	return sm
+}
+
 // WaitForShutdown waits for shutdown signals and gracefully shuts down the server
 func (sm *ShutdownManager) WaitForShutdown() error {
 	// Create a channel to receive OS signals
@@ -48,29 +66,99 @@ func (sm *ShutdownManager) WaitForShutdown() error {
 
 	// Block until we receive our signal
 	sig := <-quit
-	fmt.Printf("\nReceived signal: %v. Initiating graceful shutdown...\n", sig)
+	logging.Default().Info("received signal, initiating graceful shutdown", slog.String("signal", sig.String()))
 
 	// Create a deadline to wait for
 	ctx, cancel := context.WithTimeout(context.Background(), sm.config.Timeout)
 	defer cancel()
 
+	return sm.Shutdown(ctx)
+}
+
+// Shutdown gracefully shuts down the server within the given context.
+// The server first enters draining mode and keeps serving for the
+// configured pre-stop delay so load balancers can deregister it, then
+// stops accepting connections and waits for in-flight requests.
+func (sm *ShutdownManager) Shutdown(ctx context.Context) error {
+	if sm.tracker != nil {
+		sm.tracker.StartDraining()
+	}
+
+	// Ask clients to migrate off this instance
+	sm.server.SetKeepAlivesEnabled(false)
+
+	if sm.config.SignalTimeout > 0 {
+		logging.Default().Info("draining before shutdown", slog.Duration("delay", sm.config.SignalTimeout))
+
+		select {
+		case <-time.After(sm.config.SignalTimeout):
+		case <-ctx.Done():
+		}
+	}
+
 	// Attempt the graceful shutdown by closing the listener
 	// and completing all inflight requests
+	var errs []error
 	if err := sm.server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+		sm.logStragglers()
+		errs = append(errs, fmt.Errorf("server forced to shutdown: %w", err))
 	}
 
-	fmt.Println("Server shutdown complete")
+	// Hooks run even after a forced shutdown, so buffers are flushed and
+	// other listeners closed
+	for _, fn := range sm.onShutdown {
+		if err := fn(ctx); err != nil {
+			logging.Default().Error("shutdown hook failed", logging.Err(err))
+			errs = append(errs, fmt.Errorf("shutdown hook failed: %w", err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	logging.Default().Info("server shutdown complete")
 	return nil
 }
 
+// logStragglers logs requests that were still running when the deadline hit
+func (sm *ShutdownManager) logStragglers() {
+	if sm.tracker == nil {
+		return
+	}
+
+	stragglers := sm.tracker.Snapshot()
+	if len(stragglers) == 0 {
+		return
+	}
+
+	logger := logging.Default()
+	logger.Warn("shutdown deadline reached with requests in flight", slog.Int("in_flight", len(stragglers)))
+	for _, req := range stragglers {
+		logger.Warn("request still in flight",
+			slog.String("method", req.Method),
+			slog.String("path", req.Path),
+			slog.String("client_ip", req.ClientIP),
+			slog.Duration("running_for", req.Duration),
+		)
+	}
+}
+
 // StartWithGracefulShutdown starts the server and handles graceful shutdown
 func (sm *ShutdownManager) StartWithGracefulShutdown() error {
 	// Start server in a goroutine
 	go func() {
-		fmt.Printf("Starting server on %s\n", sm.server.Addr)
-		if err := sm.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("Server failed to start: %v\n", err)
+		var err error
+		if sm.server.TLSConfig != nil {
+			logging.Default().Info("starting HTTPS server", slog.String("addr", sm.server.Addr))
+			err = sm.server.ListenAndServeTLS("", "")
+		} else {
+			logging.Default().Info("starting server", slog.String("addr", sm.server.Addr))
+			err = sm.server.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logging.Default().Error("server failed to start", logging.Err(err))
 			os.Exit(1)
 		}
 	}()
@@ -81,12 +169,16 @@ func (sm *ShutdownManager) StartWithGracefulShutdown() error {
 
 // ForceShutdown forces immediate shutdown of the server
 func (sm *ShutdownManager) ForceShutdown() error {
-	fmt.Println("Forcing immediate server shutdown...")
+	logging.Default().Warn("forcing immediate server shutdown")
 	return sm.server.Close()
 }
 
 // setupGracefulShutdown is a helper function to setup graceful shutdown for a server
-func setupGracefulShutdown(server *http.Server) *ShutdownManager {
-	config := DefaultGracefulConfig()
+func setupGracefulShutdown(server *http.Server, config GracefulShutdownConfig) *ShutdownManager {
+	if config == (GracefulShutdownConfig{}) {
+		config = DefaultGracefulConfig()
+	} else if config.Timeout <= 0 {
+		config.Timeout = DefaultGracefulConfig().Timeout
+	}
 	return NewShutdownManager(server, config)
 }
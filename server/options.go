@@ -2,6 +2,7 @@ package server
 
 import (
 	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,30 +17,63 @@ type ServerOptions struct {
 	SetupRoutes    SetupRoutesFunc
 
 	// Optional fields with defaults
-	Config         *config.Config // If nil, will load from environment
-	Port           string         // Override config port
-	GinMode        string         // gin.ReleaseMode, gin.DebugMode, gin.TestMode
-	DisableLogging bool           // Disable request logging middleware
-	DisableCORS    bool           // Disable CORS middleware
-	DisableHealth  bool           // Disable health check endpoints
-	DisableRecover bool           // Disable recovery middleware
+	Config           *config.Config // If nil, will load from environment
+	Port             string         // Override config port
+	GinMode          string         // gin.ReleaseMode, gin.DebugMode, gin.TestMode
+	DisableLogging   bool           // Disable request logging middleware
+	DisableCORS      bool           // Disable CORS middleware
+	DisableHealth    bool           // Disable health check endpoints
+	DisableRecover   bool           // Disable recovery middleware
+	DisableRequestID bool           // Disable request ID and request-scoped logger middleware
 
 	// Advanced options
 	CustomMiddleware []gin.HandlerFunc // Additional middleware to apply
 	HealthPath       string            // Custom health check path (default: /health)
+	ReadinessPath    string            // Custom readiness path (default: /ready)
 	MetricsPath      string            // Custom metrics path (default: /metrics)
+
+	// Access log configuration (nil uses middleware.DefaultLoggingConfig)
+	AccessLog *middleware.LoggingConfig
+
+	// CORS policy, e.g. with path overrides (nil loads it from configuration)
+	CORS *middleware.CORSConfig
+
+	// Client IP allow and deny lists (nil disables)
+	IPFilter *middleware.IPFilterConfig
+
+	// Security headers for every response (nil disables)
+	SecurityHeaders *middleware.SecurityHeadersConfig
+
+	// CSRF protection for cookie-authenticated requests, e.g. from
+	// middleware.CSRFConfigFromConfig (nil disables)
+	CSRF *middleware.CSRFConfig
+
+	// Rate limiting applied to every route (nil disables)
+	RateLimit *middleware.RateLimitConfig
+
+	// Concurrency limits and load shedding for every route (nil disables)
+	LoadShedding *middleware.LoadSheddingConfig
+
+	// Deadline for every request; keep it below the server's WriteTimeout
+	// (nil disables)
+	Timeout *middleware.TimeoutConfig
+
+	// Shutdown behaviour (zero value uses DefaultGracefulConfig)
+	GracefulShutdown GracefulShutdownConfig
 }
 
 // DefaultServerOptions returns ServerOptions with sensible defaults
 func DefaultServerOptions() ServerOptions {
 	return ServerOptions{
-		GinMode:        gin.ReleaseMode,
-		DisableLogging: false,
-		DisableCORS:    false,
-		DisableHealth:  false,
-		DisableRecover: false,
-		HealthPath:     "/health",
-		MetricsPath:    "/metrics",
+		GinMode:          gin.ReleaseMode,
+		DisableLogging:   false,
+		DisableCORS:      false,
+		DisableHealth:    false,
+		DisableRecover:   false,
+		DisableRequestID: false,
+		HealthPath:       "/health",
+		ReadinessPath:    "/ready",
+		MetricsPath:      "/metrics",
 	}
 }
 
@@ -66,5 +100,23 @@ func (o *ServerOptions) Validate() error {
 		}
 	}
 
+	if o.CORS != nil {
+		if err := o.CORS.Validate(); err != nil {
+			return &ServerError{
+				Code:    "invalid_options",
+				Message: err.Error(),
+			}
+		}
+	}
+
+	if o.CSRF != nil {
+		if err := o.CSRF.Validate(); err != nil {
+			return &ServerError{
+				Code:    "invalid_options",
+				Message: err.Error(),
+			}
+		}
+	}
+
 	return nil
 }
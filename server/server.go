@@ -1,12 +1,17 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/config"
-	"github.com/gin-contrib/cors"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +22,13 @@ type Server struct {
 	config   *config.Config
 	options  ServerOptions
 	shutdown *ShutdownManager
+	tracker  *RequestTracker
+	admin    *AdminServer
+	logger   *slog.Logger
+	tracer   *tracing.Tracer // nil when tracing is disabled
+
+	health      middleware.HealthConfig // Checks reported by the detailed health endpoint
+	loadShedder *middleware.LoadShedder // nil when load shedding is disabled
 }
 
 // ServerError represents server-related errors
@@ -47,6 +59,9 @@ func NewServer(options ServerOptions) *Server {
 		panic(fmt.Sprintf("Invalid configuration: %v", err))
 	}
 
+	// Initialise structured logging from configuration
+	logger := logging.Init(cfg)
+
 	// Set Gin mode
 	if options.GinMode != "" {
 		gin.SetMode(options.GinMode)
@@ -54,14 +69,27 @@ func NewServer(options ServerOptions) *Server {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Create Gin router
+	// Create Gin router. Gin only consults forwarding headers from trusted
+	// proxies, and none are trusted unless TRUSTED_PROXIES is set.
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.ServerConfig.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("Invalid trusted proxies: %v", err))
+	}
 
 	// Create server instance
 	server := &Server{
 		router:  router,
 		config:  cfg,
 		options: options,
+		tracker: NewRequestTracker(),
+		logger:  logger,
+		health:  middleware.DefaultHealthConfig(cfg.ServiceName, cfg.ServiceVersion),
+	}
+
+	// Initialise tracing from configuration
+	if cfg.TracingConfig.Enabled {
+		server.tracer = tracing.NewTracerFromConfig(cfg)
+		tracing.SetDefault(server.tracer)
 	}
 
 	// Setup middleware
@@ -79,30 +107,111 @@ func NewServer(options ServerOptions) *Server {
 		port = cfg.Port
 	}
 
-	server.server = &http.Server{
-		Addr:         ":" + port,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	server.server = newHTTPServer(":"+port, router, cfg.ServerConfig)
+
+	// Setup TLS if configured
+	if cfg.ServerConfig.IsTLSEnabled() {
+		tlsConfig, err := buildTLSConfig(cfg.ServerConfig)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid TLS configuration: %v", err))
+		}
+		server.server.TLSConfig = tlsConfig
 	}
 
 	// Setup graceful shutdown
-	server.shutdown = setupGracefulShutdown(server.server)
+	server.shutdown = setupGracefulShutdown(server.server, options.GracefulShutdown).
+		WithRequestTracker(server.tracker)
+
+	// Flush buffered spans once requests have drained
+	if server.tracer != nil {
+		server.shutdown.RegisterOnShutdown(server.tracer.Shutdown)
+	}
+
+	// Setup admin server on its own port if configured
+	if cfg.ServerConfig.IsAdminEnabled() {
+		server.admin = newAdminServer(server, cfg.ServerConfig.AdminPort)
+		server.shutdown.RegisterOnShutdown(server.admin.Shutdown)
+	}
 
 	return server
 }
 
+// newHTTPServer creates the HTTP server. Zero values, as in hand-built
+// configurations, use the defaults and negative timeouts disable them.
+func newHTTPServer(addr string, handler http.Handler, cfg config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       serverTimeout(cfg.ReadTimeout, config.DefaultReadTimeout),
+		ReadHeaderTimeout: serverTimeout(cfg.ReadHeaderTimeout, config.DefaultReadHeaderTimeout),
+		WriteTimeout:      serverTimeout(cfg.WriteTimeout, config.DefaultWriteTimeout),
+		IdleTimeout:       serverTimeout(cfg.IdleTimeout, config.DefaultIdleTimeout),
+		MaxHeaderBytes:    intOrDefault(cfg.MaxHeaderBytes, config.DefaultMaxHeaderBytes),
+	}
+}
+
+// serverTimeout returns fallback for zero values and 0, which net/http
+// treats as no timeout, for negative ones
+func serverTimeout(value, fallback time.Duration) time.Duration {
+	switch {
+	case value == 0:
+		return fallback
+	case value < 0:
+		return 0
+	}
+	return value
+}
+
+// intOrDefault returns value unless it is zero
+func intOrDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
 // setupMiddleware configures the middleware stack
 func (s *Server) setupMiddleware() {
+	// In-flight request tracking for graceful draining
+	s.router.Use(s.tracker.Middleware())
+
 	// Recovery middleware (unless disabled)
 	if !s.options.DisableRecover {
 		s.router.Use(gin.Recovery())
 	}
 
+	// Client address, scheme and host from trusted proxies, ahead of
+	// everything that logs or acts on them
+	if len(s.config.ServerConfig.TrustedProxies) > 0 {
+		s.router.Use(middleware.NewProxyHeadersMiddleware(middleware.ProxyHeadersConfigFromConfig(s.config)))
+	}
+
+	// Tracing, ahead of request ID so the trace ID can be reused
+	if s.tracer != nil {
+		tracingConfig := middleware.DefaultTracingConfig()
+		tracingConfig.Tracer = s.tracer
+		s.router.Use(middleware.NewTracingMiddleware(tracingConfig))
+	}
+
+	// Request ID and request-scoped logger (unless disabled)
+	if !s.options.DisableRequestID {
+		s.router.Use(middleware.DefaultRequestIDMiddleware())
+		s.router.Use(middleware.ContextLoggerMiddleware())
+	}
+
 	// Logging middleware (unless disabled)
 	if !s.options.DisableLogging {
-		s.router.Use(gin.Logger())
+		accessLog := middleware.DefaultLoggingConfig()
+		accessLog.Body = middleware.BodyLogConfigFromConfig(s.config)
+		if s.options.AccessLog != nil {
+			accessLog = *s.options.AccessLog
+		}
+		s.router.Use(middleware.NewLoggingMiddleware(accessLog))
+	}
+
+	// IP allow and deny lists, after logging so rejections are logged
+	if s.options.IPFilter != nil {
+		s.router.Use(middleware.NewIPFilterMiddleware(*s.options.IPFilter))
 	}
 
 	// CORS middleware (unless disabled)
@@ -110,23 +219,49 @@ func (s *Server) setupMiddleware() {
 		s.setupCORS()
 	}
 
+	// Security headers, so every response carries them, including rejections
+	if s.options.SecurityHeaders != nil {
+		s.router.Use(middleware.NewSecurityHeadersMiddleware(*s.options.SecurityHeaders))
+	}
+
+	// CSRF protection, after CORS so preflight requests are answered first
+	if s.options.CSRF != nil {
+		s.router.Use(middleware.NewCSRFMiddleware(*s.options.CSRF))
+	}
+
+	// Rate limiting, after CORS so rejected requests still carry CORS headers
+	if s.options.RateLimit != nil {
+		s.router.Use(middleware.NewRateLimitMiddleware(*s.options.RateLimit))
+	}
+
+	// Load shedding, after rate limiting so abusive clients are turned away
+	// before they take a slot
+	if s.options.LoadShedding != nil {
+		s.loadShedder = middleware.NewLoadShedder(*s.options.LoadShedding)
+		s.loadShedder.RegisterHealthCheck(&s.health)
+		s.router.Use(s.loadShedder.Middleware())
+	}
+
+	// Request deadline, once a request is admitted so queueing is not counted
+	if s.options.Timeout != nil {
+		s.router.Use(middleware.NewTimeoutMiddleware(*s.options.Timeout))
+	}
+
 	// Custom middleware
 	for _, middleware := range s.options.CustomMiddleware {
 		s.router.Use(middleware)
 	}
 }
 
-// setupCORS configures CORS middleware
+// setupCORS configures CORS middleware from ServerOptions.CORS, or from
+// configuration when unset
 func (s *Server) setupCORS() {
-	corsConfig := cors.Config{
-		AllowOrigins:     s.config.AllowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	corsConfig := middleware.CORSConfigFromConfig(s.config)
+	if s.options.CORS != nil {
+		corsConfig = *s.options.CORS
 	}
 
-	s.router.Use(cors.New(corsConfig))
+	s.router.Use(middleware.NewCORSMiddleware(corsConfig))
 }
 
 // setupDefaultRoutes sets up default routes like health checks
@@ -147,6 +282,32 @@ func (s *Server) setupHealthRoutes() {
 
 	// Detailed health check
 	s.router.GET(healthPath+"/detailed", s.detailedHealthHandler)
+
+	readinessPath := s.options.ReadinessPath
+	if readinessPath == "" {
+		readinessPath = "/ready"
+	}
+
+	// Readiness check (reports 503 while draining)
+	s.router.GET(readinessPath, s.readinessHandler)
+}
+
+// readinessHandler reports whether the server should receive traffic
+func (s *Server) readinessHandler(c *gin.Context) {
+	if s.tracker.IsDraining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "draining",
+			"service":   s.config.ServiceName,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ready",
+		"service":   s.config.ServiceName,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
 }
 
 // healthCheckHandler handles basic health checks
@@ -159,15 +320,24 @@ func (s *Server) healthCheckHandler(c *gin.Context) {
 	})
 }
 
-// detailedHealthHandler handles detailed health checks
+// detailedHealthHandler handles detailed health checks, including the
+// registered health checkers
 func (s *Server) detailedHealthHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":      "healthy",
+	status, checks := s.health.RunHealthChecks()
+
+	statusCode := http.StatusOK
+	if status == middleware.HealthStatusUnhealthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, gin.H{
+		"status":      status,
 		"service":     s.config.ServiceName,
 		"version":     s.config.ServiceVersion,
 		"environment": s.config.Environment,
 		"timestamp":   time.Now().UTC().Format(time.RFC3339),
-		"uptime":      time.Since(time.Now()).String(), // This would need to be tracked properly
+		"uptime":      time.Since(s.health.StartTime).String(),
+		"checks":      checks,
 		"config": gin.H{
 			"port":        s.config.Port,
 			"log_level":   s.config.LogLevel,
@@ -178,18 +348,51 @@ func (s *Server) detailedHealthHandler(c *gin.Context) {
 
 // Start starts the server with graceful shutdown handling
 func (s *Server) Start() error {
-	fmt.Printf("Starting %s v%s\n", s.config.ServiceName, s.config.ServiceVersion)
-	fmt.Printf("Environment: %s\n", s.config.Environment)
-	fmt.Printf("Log Level: %s\n", s.config.LogLevel)
+	s.logger.Info("starting service",
+		slog.String("environment", s.config.Environment),
+		slog.String("log_level", s.config.LogLevel),
+	)
+
+	logging.EnableSIGHUPToggle()
+
+	if err := s.StartAdmin(); err != nil {
+		return err
+	}
 
 	return s.shutdown.StartWithGracefulShutdown()
 }
 
+// StartAdmin starts the admin server in the background if it is enabled
+func (s *Server) StartAdmin() error {
+	if s.admin == nil {
+		return nil
+	}
+	return s.admin.Start()
+}
+
 // Stop stops the server gracefully
 func (s *Server) Stop() error {
 	return s.shutdown.WaitForShutdown()
 }
 
+// Serve serves HTTP requests on the given listener until the server is shut down
+func (s *Server) Serve(ln net.Listener) error {
+	s.logger.Info("starting server",
+		slog.String("addr", ln.Addr().String()),
+		slog.Bool("tls", s.server.TLSConfig != nil),
+	)
+
+	if s.server.TLSConfig != nil {
+		return s.server.ServeTLS(ln, "", "")
+	}
+	return s.server.Serve(ln)
+}
+
+// Shutdown gracefully shuts down the server within the given context
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.shutdown.Shutdown(ctx)
+}
+
 // ForceStop forces immediate server shutdown
 func (s *Server) ForceStop() error {
 	return s.shutdown.ForceShutdown()
@@ -205,6 +408,54 @@ func (s *Server) GetConfig() *config.Config {
 	return s.config
 }
 
+// GetAdminServer returns the admin server, or nil if it is disabled
+func (s *Server) GetAdminServer() *AdminServer {
+	return s.admin
+}
+
+// Routes returns the routes registered on the public router
+func (s *Server) Routes() []RouteInfo {
+	routes := s.router.Routes()
+	result := make([]RouteInfo, 0, len(routes))
+
+	for _, route := range routes {
+		result = append(result, RouteInfo{
+			Method:  route.Method,
+			Path:    route.Path,
+			Handler: route.Handler,
+		})
+	}
+
+	return result
+}
+
+// GetRequestTracker returns the in-flight request tracker
+func (s *Server) GetRequestTracker() *RequestTracker {
+	return s.tracker
+}
+
+// GetLogger returns the service logger
+func (s *Server) GetLogger() *slog.Logger {
+	return s.logger
+}
+
+// GetTracer returns the server's tracer, or nil if tracing is disabled
+func (s *Server) GetTracer() *tracing.Tracer {
+	return s.tracer
+}
+
+// GetLoadShedder returns the server's load shedder, or nil if load shedding
+// is disabled
+func (s *Server) GetLoadShedder() *middleware.LoadShedder {
+	return s.loadShedder
+}
+
+// AddHealthChecker adds a checker to the detailed health endpoint. Call it
+// before Start.
+func (s *Server) AddHealthChecker(name string, checker middleware.HealthChecker) {
+	s.health.AddHealthChecker(name, checker)
+}
+
 // GetHTTPServer returns the underlying HTTP server
 func (s *Server) GetHTTPServer() *http.Server {
 	return s.server
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+// CertReloader serves a TLS certificate and reloads it from disk when the
+// certificate or key file changes, so rotated certificates are picked up
+// without a restart
+type CertReloader struct {
+	certFile  string
+	keyFile   string
+	interval  time.Duration
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader loads the certificate and creates a reloader
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	reloader := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Reload loads the certificate and key from disk
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if
+// the files changed since the last check. It is meant to be used as
+// tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert := r.cert
+	due := r.interval > 0 && time.Since(r.lastCheck) >= r.interval
+	r.mu.RUnlock()
+
+	if due {
+		r.reloadIfChanged()
+		r.mu.RLock()
+		cert = r.cert
+		r.mu.RUnlock()
+	}
+
+	return cert, nil
+}
+
+// reloadIfChanged reloads the certificate when the files were modified.
+// On failure the previous certificate keeps being served.
+func (r *CertReloader) reloadIfChanged() {
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	current := r.modTime
+	r.mu.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil || !modTime.After(current) {
+		return
+	}
+
+	if err := r.Reload(); err != nil {
+		logging.Default().Error("failed to reload TLS certificate, keeping previous one", logging.Err(err))
+		return
+	}
+
+	logging.Default().Info("TLS certificate reloaded")
+}
+
+// latestModTime returns the most recent modification time of the cert and key files
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// buildTLSConfig creates the TLS configuration for the server
+func buildTLSConfig(cfg config.ServerConfig) (*tls.Config, error) {
+	reloader, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     cfg.GetTLSMinVersion(),
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.IsMutualTLS() {
+		caPEM, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates in client CA file")
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
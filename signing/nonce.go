@@ -0,0 +1,67 @@
+// signing/nonce.go
+package signing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore records seen nonces. Implementations backed by a shared store
+// let replicas recognise requests replayed to another replica.
+type NonceStore interface {
+	// Remember records nonce for ttl and reports whether it was unseen
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore keeps seen nonces in process
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time // Nonce to expiry
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore creates an in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces:    make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Remember implements NonceStore
+func (s *MemoryNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	if expiresAt, exists := s.nonces[nonce]; exists && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// Len returns the number of nonces held
+func (s *MemoryNonceStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.nonces)
+}
+
+// sweepLocked drops expired nonces at most once a minute. Callers hold mu.
+func (s *MemoryNonceStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for nonce, expiresAt := range s.nonces {
+		if !now.Before(expiresAt) {
+			delete(s.nonces, nonce)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// signing/transport.go
+package signing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TransportConfig holds configuration for a signing round tripper
+type TransportConfig struct {
+	Keys *Keyring          // Requests are signed with the primary key
+	Next http.RoundTripper // Wrapped transport (default: http.DefaultTransport)
+}
+
+// NewTransport returns a round tripper that signs every request with the
+// keyring's primary key. Use it as client.Config.Transport for calls to
+// services protected by middleware.NewSignatureMiddleware. Retries are
+// signed afresh, each with a new nonce.
+func NewTransport(config TransportConfig) http.RoundTripper {
+	if config.Next == nil {
+		config.Next = http.DefaultTransport
+	}
+	return &transport{config: config}
+}
+
+type transport struct {
+	config TransportConfig
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := t.config.Keys.Primary()
+	if !ok {
+		return nil, fmt.Errorf("no signing key configured")
+	}
+
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+		signed.ContentLength = int64(len(body))
+	}
+	if err := Sign(signed, body, key); err != nil {
+		return nil, err
+	}
+
+	return t.config.Next.RoundTrip(signed)
+}
+
+// readBody reads the request body, closing the original as RoundTrip must
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,87 @@
+// signing/verify.go
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxNonceLength bounds nonces held by the nonce store
+const maxNonceLength = 128
+
+// VerifierConfig holds configuration for signature verification
+type VerifierConfig struct {
+	Keys    *Keyring
+	MaxSkew time.Duration // Largest accepted clock difference (default: 5m)
+	Nonces  NonceStore    // Seen nonces (default: in-memory)
+}
+
+// Verifier checks request signatures
+type Verifier struct {
+	config VerifierConfig
+}
+
+// NewVerifier creates a verifier. It returns an error without a keyring.
+func NewVerifier(config VerifierConfig) (*Verifier, error) {
+	if config.Keys == nil {
+		return nil, fmt.Errorf("verifier requires a keyring")
+	}
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = 5 * time.Minute
+	}
+	if config.Nonces == nil {
+		config.Nonces = NewMemoryNonceStore()
+	}
+	return &Verifier{config: config}, nil
+}
+
+// Verify checks the signature of req, whose body is body, and returns the
+// ID of the key it was signed with. Timestamps further than MaxSkew from
+// now are rejected, and each nonce is only accepted once per key.
+func (v *Verifier) Verify(ctx context.Context, req *http.Request, body []byte) (string, error) {
+	keyID := req.Header.Get(HeaderKeyID)
+	timestamp := req.Header.Get(HeaderTimestamp)
+	nonce := req.Header.Get(HeaderNonce)
+	signature := req.Header.Get(HeaderSignature)
+	if keyID == "" && timestamp == "" && nonce == "" && signature == "" {
+		return "", ErrMissingSignature
+	}
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" || len(nonce) > maxNonceLength {
+		return "", ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	skew := time.Since(time.Unix(seconds, 0))
+	if skew > v.config.MaxSkew || skew < -v.config.MaxSkew {
+		return "", ErrStaleTimestamp
+	}
+
+	key, exists := v.config.Keys.Lookup(keyID)
+	if !exists {
+		return "", ErrUnknownKey
+	}
+
+	expected := computeSignature(key, canonicalRequest(req, timestamp, nonce, keyID, body))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", ErrInvalidSignature
+	}
+
+	// Nonces are recorded only for authentic requests, so forged requests
+	// cannot burn them. They are kept for as long as the timestamp is valid.
+	fresh, err := v.config.Nonces.Remember(ctx, keyID+":"+nonce, 2*v.config.MaxSkew)
+	if err != nil {
+		return "", fmt.Errorf("failed to check nonce: %w", err)
+	}
+	if !fresh {
+		return "", ErrReplayedNonce
+	}
+
+	return keyID, nil
+}
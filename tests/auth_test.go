@@ -0,0 +1,389 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/auth"
+	"github.com/JorgeSaicoski/microservice-commons/client"
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// newTokenEndpoint starts a stand-in for the Keycloak token endpoint that
+// issues numbered tokens
+func newTokenEndpoint(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/realms/services/protocol/openid-connect/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "orders" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &issued
+}
+
+func keycloakConfig(url string) config.KeycloakConfig {
+	return config.KeycloakConfig{
+		URL:          url,
+		Realm:        "services",
+		HTTPTimeout:  time.Second,
+		ClientID:     "orders",
+		ClientSecret: "s3cret",
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	server, issued := newTokenEndpoint(t, 300)
+
+	source, err := auth.NewKeycloakTokenSource(keycloakConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Expected token source, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := source.Token(context.Background())
+			if err != nil {
+				t.Errorf("Expected token, got %v", err)
+				return
+			}
+			tokens[i] = token.AccessToken
+		}(i)
+	}
+	wg.Wait()
+
+	if issued.Load() != 1 {
+		t.Errorf("Expected concurrent callers to share one request, got %d", issued.Load())
+	}
+	for _, token := range tokens {
+		if token != "token-1" {
+			t.Errorf("Expected cached token-1, got %q", token)
+		}
+	}
+
+	source.Invalidate()
+	token, err := source.Token(context.Background())
+	if err != nil || token.AccessToken != "token-2" {
+		t.Errorf("Expected new token after invalidation, got %v %v", token, err)
+	}
+}
+
+func TestClientCredentialsRefreshBeforeExpiry(t *testing.T) {
+	server, issued := newTokenEndpoint(t, 1)
+
+	cfg := auth.ClientCredentialsConfigFromKeycloak(keycloakConfig(server.URL))
+	cfg.RefreshBefore = 900 * time.Millisecond
+	source, err := auth.NewClientCredentialsSource(cfg)
+	if err != nil {
+		t.Fatalf("Expected token source, got %v", err)
+	}
+
+	if _, err := source.Token(context.Background()); err != nil {
+		t.Fatalf("Expected token, got %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	// Still valid, so the cached token is returned while a refresh starts
+	token, err := source.Token(context.Background())
+	if err != nil || token.AccessToken != "token-1" {
+		t.Fatalf("Expected cached token during early refresh, got %v %v", token, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for issued.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	token, _ = source.Token(context.Background())
+	if token == nil || token.AccessToken != "token-2" {
+		t.Errorf("Expected refreshed token-2, got %v", token)
+	}
+}
+
+func TestClientCredentialsTransport(t *testing.T) {
+	server, _ := newTokenEndpoint(t, 300)
+
+	var authorizations []string
+	var mu sync.Mutex
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		first := len(authorizations) == 1
+		mu.Unlock()
+
+		// Reject the first token as if it had been revoked
+		if first {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	source, err := auth.NewKeycloakTokenSource(keycloakConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Expected token source, got %v", err)
+	}
+
+	cfg := client.DefaultConfig()
+	cfg.BaseURL = upstream.URL
+	cfg.PropagateToken = false
+	cfg.Transport = auth.NewTransport(auth.TransportConfig{Source: source})
+
+	if err := client.New(cfg).Post(context.Background(), "/events", map[string]string{"type": "created"}, nil); err != nil {
+		t.Fatalf("Expected call to succeed with a fresh token, got %v", err)
+	}
+
+	if len(authorizations) != 2 || authorizations[0] != "Bearer token-1" || authorizations[1] != "Bearer token-2" {
+		t.Errorf("Expected retry with a fresh token, got %v", authorizations)
+	}
+}
+
+func TestClientCredentialsRejected(t *testing.T) {
+	server, _ := newTokenEndpoint(t, 300)
+
+	kc := keycloakConfig(server.URL)
+	kc.ClientSecret = "wrong"
+	source, err := auth.NewKeycloakTokenSource(kc)
+	if err != nil {
+		t.Fatalf("Expected token source, got %v", err)
+	}
+
+	_, err = source.Token(context.Background())
+	var tokenErr *auth.TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_client" {
+		t.Errorf("Expected invalid_client token error, got %v", err)
+	}
+}
+
+// newIntrospectionEndpoint starts a stand-in for the Keycloak introspection
+// endpoint. Tokens named "active-*" are active; down simulates an outage.
+func newIntrospectionEndpoint(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Bool) {
+	t.Helper()
+
+	var calls atomic.Int32
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if id, secret, _ := r.BasicAuth(); id != "orders" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		token := r.FormValue("token")
+		if !strings.HasPrefix(token, "active-") {
+			fmt.Fprint(w, `{"active":false}`)
+			return
+		}
+		fmt.Fprintf(w, `{"active":true,"sub":"user-1","scope":"orders:read orders:write","exp":%d,"realm_access":{"roles":["admin"]}}`,
+			time.Now().Add(time.Hour).Unix())
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls, &down
+}
+
+func TestIntrospectionValidator(t *testing.T) {
+	server, calls, _ := newIntrospectionEndpoint(t)
+
+	validator, err := auth.NewKeycloakIntrospectionValidator(keycloakConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Expected validator, got %v", err)
+	}
+
+	claims, err := validator.Validate("active-1")
+	if err != nil {
+		t.Fatalf("Expected active token to validate, got %v", err)
+	}
+	if claims["user_id"] != "user-1" {
+		t.Errorf("Expected sub mapped to user_id, got %v", claims["user_id"])
+	}
+	if scopes, _ := claims["scopes"].([]string); len(scopes) != 2 || scopes[0] != "orders:read" {
+		t.Errorf("Expected scopes to be split, got %v", claims["scopes"])
+	}
+	if roles, _ := claims["roles"].([]string); len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("Expected realm roles, got %v", claims["roles"])
+	}
+	if _, ok := claims["exp"].(time.Time); !ok {
+		t.Errorf("Expected exp as time.Time, got %T", claims["exp"])
+	}
+
+	if _, err := validator.Validate("opaque-2"); err != middleware.ErrInvalidToken {
+		t.Errorf("Expected inactive token to be rejected, got %v", err)
+	}
+
+	// Both results are cached
+	validator.Validate("active-1")
+	validator.Validate("opaque-2")
+	if calls.Load() != 2 {
+		t.Errorf("Expected cached results to be reused, got %d calls", calls.Load())
+	}
+}
+
+func TestIntrospectionOutagePolicies(t *testing.T) {
+	server, _, down := newIntrospectionEndpoint(t)
+
+	newValidator := func(policy auth.OutagePolicy) *auth.IntrospectionValidator {
+		cfg := auth.IntrospectionConfigFromKeycloak(keycloakConfig(server.URL))
+		cfg.CacheTTL = time.Millisecond
+		cfg.OutagePolicy = policy
+		validator, err := auth.NewIntrospectionValidator(cfg)
+		if err != nil {
+			t.Fatalf("Expected validator, got %v", err)
+		}
+		return validator
+	}
+
+	closed := newValidator(auth.OutageFailClosed)
+	cached := newValidator(auth.OutageUseCached)
+	open := newValidator(auth.OutageFailOpen)
+
+	down.Store(false)
+	closed.Validate("active-1")
+	cached.Validate("active-1")
+	time.Sleep(5 * time.Millisecond)
+	down.Store(true)
+
+	if _, err := closed.Validate("active-1"); err != auth.ErrIntrospectionUnavailable {
+		t.Errorf("Expected fail closed to reject, got %v", err)
+	}
+	if claims, err := cached.Validate("active-1"); err != nil || claims["user_id"] != "user-1" {
+		t.Errorf("Expected use cached to accept a recently active token, got %v %v", claims, err)
+	}
+	if _, err := cached.Validate("active-unknown"); err != auth.ErrIntrospectionUnavailable {
+		t.Errorf("Expected use cached to reject unknown tokens, got %v", err)
+	}
+	if claims, err := open.Validate("garbage"); err != middleware.ErrUnverifiedToken || claims != nil {
+		t.Errorf("Expected fail open to report the token as unverified, got %v %v", claims, err)
+	}
+
+	// Unverified tokens are rejected unless a route opts in, and never
+	// authenticate the caller
+	gin.SetMode(gin.TestMode)
+	for _, allow := range []bool{false, true} {
+		authConfig := middleware.DefaultAuthConfig()
+		authConfig.TokenValidator = open.Validate
+		authConfig.AllowUnverified = allow
+		router := gin.New()
+		router.Use(middleware.NewAuthMiddleware(authConfig))
+		router.GET("/feed", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "unverified": c.GetBool(middleware.AuthUnverifiedKey)})
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+		req.Header.Set("Authorization", "Bearer garbage")
+		router.ServeHTTP(w, req)
+
+		if !allow && w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected unverified tokens to be rejected by default, got %d", w.Code)
+		}
+		if allow && (w.Code != http.StatusOK || w.Body.String() != `{"unverified":true,"user_id":""}`) {
+			t.Errorf("Expected an anonymous request, got %d %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestTokenRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	issuedAt := time.Now().Add(-time.Hour)
+	store := auth.NewMemoryRevocationStore()
+
+	authConfig := middleware.DefaultAuthConfig()
+	authConfig.RevocationChecker = store
+	authConfig.TokenValidator = func(token string) (map[string]interface{}, error) {
+		subject, jti, _ := strings.Cut(token, ":")
+		return map[string]interface{}{
+			"sub": subject,
+			"jti": jti,
+			"iat": float64(issuedAt.Unix()),
+		}, nil
+	}
+
+	router := gin.New()
+	router.Use(middleware.NewAuthMiddleware(authConfig))
+	router.GET("/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := call("alice:t1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected valid token to pass, got %d", w.Code)
+	}
+
+	store.RevokeToken(context.Background(), "t1", time.Now().Add(time.Hour))
+	w := call("alice:t1")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "revoked_token") {
+		t.Errorf("Expected revoked token to be rejected, got %d %s", w.Code, w.Body.String())
+	}
+	if w := call("alice:t2"); w.Code != http.StatusOK {
+		t.Errorf("Expected other tokens to pass, got %d", w.Code)
+	}
+
+	// Logging out everywhere revokes every token issued before now
+	store.RevokeSubject(context.Background(), "alice", time.Now())
+	if w := call("alice:t2"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected tokens issued before logout to be rejected, got %d", w.Code)
+	}
+
+	issuedAt = time.Now().Add(time.Second)
+	if w := call("alice:t3"); w.Code != http.StatusOK {
+		t.Errorf("Expected tokens issued after logout to pass, got %d", w.Code)
+	}
+
+	// Optional authentication treats revoked tokens as anonymous
+	optional := authConfig
+	optional.Optional = true
+	router = gin.New()
+	router.Use(middleware.NewAuthMiddleware(optional))
+	router.GET("/me", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("sub")) })
+
+	if w := call("alice:t1"); w.Code != http.StatusOK || w.Body.String() != "" {
+		t.Errorf("Expected a revoked token to be anonymous, got %d %q", w.Code, w.Body.String())
+	}
+	if w := call("bob:t4"); w.Code != http.StatusOK || w.Body.String() != "bob" {
+		t.Errorf("Expected a valid token to authenticate, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/client"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientPropagatesContext(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"7"}`))
+	}))
+	defer upstream.Close()
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	ctx = middleware.ContextWithToken(ctx, "caller-token")
+	ctx, span := tracing.Start(ctx, "handler", tracing.SpanKindServer)
+	defer span.End()
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := client.NewDefault(upstream.URL).Get(ctx, "/orders/7", &out); err != nil {
+		t.Fatalf("Expected successful call, got %v", err)
+	}
+
+	if out.ID != "7" {
+		t.Errorf("Expected decoded response, got %+v", out)
+	}
+	if received.Get("X-Request-ID") != "req-42" {
+		t.Errorf("Expected request ID to be propagated, got %q", received.Get("X-Request-ID"))
+	}
+	if received.Get("Authorization") != "Bearer caller-token" {
+		t.Errorf("Expected caller token to be propagated, got %q", received.Get("Authorization"))
+	}
+
+	sc, err := tracing.ParseTraceparent(received.Get("traceparent"))
+	if err != nil {
+		t.Fatalf("Expected valid traceparent, got %v", err)
+	}
+	if sc.TraceID != span.SpanContext().TraceID {
+		t.Error("Expected outbound call to continue the caller's trace")
+	}
+}
+
+func TestClientTokenOnlySentToTrustedHosts(t *testing.T) {
+	var received atomic.Value
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer thirdParty.Close()
+
+	ctx := middleware.ContextWithToken(context.Background(), "caller-token")
+
+	orders := client.NewDefault("http://orders-service:8000")
+	if err := orders.Get(ctx, thirdParty.URL+"/webhook", nil); err != nil {
+		t.Fatalf("Expected successful call, got %v", err)
+	}
+	if token := received.Load(); token != "" {
+		t.Errorf("Expected no token for another host, got %q", token)
+	}
+
+	config := client.DefaultConfig()
+	config.TokenHosts = []string{strings.TrimPrefix(thirdParty.URL, "http://")}
+	if err := client.New(config).Get(ctx, thirdParty.URL+"/webhook", nil); err != nil {
+		t.Fatalf("Expected successful call, got %v", err)
+	}
+	if token := received.Load(); token != "Bearer caller-token" {
+		t.Errorf("Expected the token for an allowed host, got %q", token)
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	config := client.DefaultConfig()
+	config.BaseURL = upstream.URL
+	config.InitialBackoff = time.Millisecond
+	c := client.New(config)
+
+	if err := c.Get(context.Background(), "/items", nil); err != nil {
+		t.Fatalf("Expected GET to succeed after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+
+	// POST without an idempotency key is sent once
+	calls.Store(0)
+	err := c.Post(context.Background(), "/items", map[string]string{"name": "x"}, nil)
+	if err == nil {
+		t.Fatal("Expected POST to fail with 503")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected POST not to be retried, got %d attempts", calls.Load())
+	}
+}
+
+func TestClientDecodesErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/orders/:id", func(c *gin.Context) {
+		responses.NotFound(c, "Order not found")
+	})
+	upstream := httptest.NewServer(router)
+	defer upstream.Close()
+
+	err := client.NewDefault(upstream.URL).Get(context.Background(), "/orders/9", nil)
+
+	apiErr, ok := client.AsAPIError(err)
+	if !ok {
+		t.Fatalf("Expected *responses.APIError, got %T (%v)", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != responses.ErrCodeNotFound {
+		t.Errorf("Expected 404 not_found, got %d %s", apiErr.StatusCode, apiErr.Code)
+	}
+	if apiErr.Message != "Order not found" {
+		t.Errorf("Expected message from envelope, got %q", apiErr.Message)
+	}
+}
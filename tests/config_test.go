@@ -3,6 +3,7 @@ package test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/config"
 )
@@ -161,3 +162,64 @@ func TestEnvironmentCheckers(t *testing.T) {
 		})
 	}
 }
+
+func TestServerConfigValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      config.ServerConfig
+		expectError bool
+	}{
+		{
+			name:        "zero values use defaults",
+			config:      config.ServerConfig{},
+			expectError: false,
+		},
+		{
+			name: "negative timeout disables it",
+			config: config.ServerConfig{
+				WriteTimeout: -1 * time.Second,
+			},
+			expectError: false,
+		},
+		{
+			name: "negative read header timeout",
+			config: config.ServerConfig{
+				ReadHeaderTimeout: -1 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			name: "cert without key",
+			config: config.ServerConfig{
+				TLSCertFile: "cert.pem",
+			},
+			expectError: true,
+		},
+		{
+			name: "client CA without TLS",
+			config: config.ServerConfig{
+				TLSClientCAFile: "ca.pem",
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported TLS version",
+			config: config.ServerConfig{
+				TLSMinVersion: "2.0",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func corsPreflight(router *gin.Engine, path, origin string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSWildcardSubdomains(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cors := middleware.DefaultCORSConfig()
+	cors.AllowedOrigins = []string{"https://app.example.org", "https://*.example.com"}
+	router := gin.New()
+	router.Use(middleware.NewCORSMiddleware(cors))
+	router.POST("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := map[string]bool{
+		"https://app.example.org":      true,
+		"https://api.example.com":      true,
+		"https://a.b.example.com":      true,
+		"https://example.com":          false,
+		"http://api.example.com":       false,
+		"https://api.example.com:8443": false,
+		"https://example.com.evil.net": false,
+		"https://evilexample.com":      false,
+		"https://other.example.org":    false,
+	}
+	for origin, allowed := range tests {
+		w := corsPreflight(router, "/items", origin)
+		got := w.Header().Get("Access-Control-Allow-Origin") == origin
+		if got != allowed {
+			t.Errorf("%s: expected allowed=%v, got status %d", origin, allowed, w.Code)
+		}
+	}
+
+	w := corsPreflight(router, "/items", "https://api.example.com")
+	if w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Error("Expected credentials to be allowed")
+	}
+}
+
+func TestCORSPathOverrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cors := middleware.DefaultCORSConfig()
+	cors.AllowedOrigins = []string{"https://app.example.com"}
+	public := middleware.DefaultCORSConfig()
+	public.AllowedOrigins = []string{"*"}
+	public.AllowCredentials = false
+	cors.PathOverrides = map[string]middleware.CORSConfig{"/public/": public}
+
+	router := gin.New()
+	router.Use(middleware.NewCORSMiddleware(cors))
+	router.POST("/public/feed", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/private", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	if w := corsPreflight(router, "/public/feed", "https://anyone.net"); w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Expected the override to allow any origin, got %v", w.Header())
+	}
+	if w := corsPreflight(router, "/private", "https://anyone.net"); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected other paths to keep the default policy")
+	}
+}
+
+func TestCORSValidation(t *testing.T) {
+	cors := middleware.DefaultCORSConfig()
+	cors.AllowedOrigins = []string{"*"}
+	if cors.Validate() == nil {
+		t.Error("Expected \"*\" with credentials to be rejected")
+	}
+
+	cors.AllowCredentials = false
+	if err := cors.Validate(); err != nil {
+		t.Errorf("Expected \"*\" without credentials to be valid, got %v", err)
+	}
+
+	for _, origin := range []string{"example.com", "https://example.com/path", "https://*.com", "https://a.*.example.com", "https://*example.com"} {
+		if config.ValidateOrigin(origin) == nil {
+			t.Errorf("Expected origin %q to be rejected", origin)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid configuration to panic")
+		}
+	}()
+	middleware.NewCORSMiddleware(middleware.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORSConfigFromEnv(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://*.example.com")
+	t.Setenv("CORS_ALLOWED_METHODS", "GET, POST")
+	t.Setenv("CORS_EXPOSED_HEADERS", "X-Request-ID")
+	t.Setenv("CORS_MAX_AGE", "30m")
+
+	cfg := config.LoadFromEnv()
+	cors := middleware.CORSConfigFromConfig(cfg)
+
+	if len(cors.AllowedMethods) != 2 || cors.AllowedMethods[1] != "POST" {
+		t.Errorf("Expected methods from env, got %v", cors.AllowedMethods)
+	}
+	if len(cors.ExposedHeaders) != 1 || cors.MaxAge != 30*time.Minute || !cors.AllowCredentials {
+		t.Errorf("Unexpected CORS config %+v", cors)
+	}
+	if err := cfg.CORSConfig.Validate(cfg.AllowedOrigins); err != nil {
+		t.Errorf("Expected wildcard origins to be valid, got %v", err)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", "*")
+	cfg = config.LoadFromEnv()
+	if cfg.CORSConfig.Validate(cfg.AllowedOrigins) == nil {
+		t.Error("Expected \"*\" with credentials to fail validation")
+	}
+}
+
+func TestCORSHandBuiltConfigAllowsCredentials(t *testing.T) {
+	cfg := &config.Config{AllowedOrigins: []string{"https://app.example.com"}}
+	if cors := middleware.CORSConfigFromConfig(cfg); !cors.AllowCredentials {
+		t.Error("Expected a hand-built configuration to keep credentials, as the server always did")
+	}
+
+	cfg.CORSConfig.DisableCredentials = true
+	if cors := middleware.CORSConfigFromConfig(cfg); cors.AllowCredentials {
+		t.Error("Expected credentials to be disabled when asked")
+	}
+
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	if config.LoadCORSConfig().AllowsCredentials() {
+		t.Error("Expected CORS_ALLOW_CREDENTIALS=false to disable credentials")
+	}
+}
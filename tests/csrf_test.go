@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewCSRFMiddleware(middleware.DefaultCSRFConfig()))
+	router.GET("/csrf", middleware.CSRFTokenHandler)
+	router.POST("/transfer", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "http://bank.example.com"+path, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "/csrf", "", nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "csrf_token" || cookies[0].Value == "" {
+		t.Fatalf("Expected a CSRF cookie to be issued, got %v", cookies)
+	}
+	token := cookies[0].Value
+	if !strings.Contains(w.Body.String(), token) {
+		t.Errorf("Expected the token in the response, got %s", w.Body.String())
+	}
+	cookie := "csrf_token=" + token
+
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Cookie": cookie}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a missing token to be rejected, got %d", w.Code)
+	}
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Cookie": cookie, "X-CSRF-Token": "forged"}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a wrong token to be rejected, got %d", w.Code)
+	}
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Cookie": cookie, "X-CSRF-Token": token}); w.Code != http.StatusOK {
+		t.Errorf("Expected a matching header to pass, got %d", w.Code)
+	}
+
+	form := url.Values{"csrf_token": {token}}.Encode()
+	headers := map[string]string{"Cookie": cookie, "Content-Type": "application/x-www-form-urlencoded"}
+	if w := request(http.MethodPost, "/transfer", form, headers); w.Code != http.StatusOK {
+		t.Errorf("Expected a matching form field to pass, got %d", w.Code)
+	}
+}
+
+func TestCSRFOriginCheck(t *testing.T) {
+	config := middleware.DefaultCSRFConfig()
+	config.AllowedOrigins = []string{"https://*.example.org"}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewCSRFMiddleware(config))
+	router.GET("/csrf", middleware.CSRFTokenHandler)
+	router.POST("/transfer", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "http://bank.example.com"+path, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	token := request(http.MethodGet, "/csrf", "", nil).Result().Cookies()[0].Value
+
+	tests := map[string]int{
+		"http://bank.example.com":  http.StatusOK,
+		"https://app.example.org":  http.StatusOK,
+		"https://evil.example.net": http.StatusForbidden,
+		"null":                     http.StatusForbidden,
+	}
+	for origin, expected := range tests {
+		headers := map[string]string{"Cookie": "csrf_token=" + token, "X-CSRF-Token": token, "Origin": origin}
+		if w := request(http.MethodPost, "/transfer", "", headers); w.Code != expected {
+			t.Errorf("%s: expected %d, got %d", origin, expected, w.Code)
+		}
+	}
+
+	headers := map[string]string{"Cookie": "csrf_token=" + token, "X-CSRF-Token": token, "Referer": "https://evil.example.net/page"}
+	if w := request(http.MethodPost, "/transfer", "", headers); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a cross-site Referer to be rejected, got %d", w.Code)
+	}
+}
+
+func TestCSRFExemptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewCSRFMiddleware(middleware.DefaultCSRFConfig()))
+	router.GET("/csrf", middleware.CSRFTokenHandler)
+	router.POST("/transfer", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "http://bank.example.com"+path, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, headers := range []map[string]string{
+		{"Authorization": "Bearer token"},
+		{"X-API-Key": "key"},
+	} {
+		if w := request(http.MethodPost, "/transfer", "", headers); w.Code != http.StatusOK {
+			t.Errorf("Expected %v to be exempt, got %d", headers, w.Code)
+		}
+	}
+
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected basic auth not to be exempt, got %d", w.Code)
+	}
+}
+
+func TestCSRFSynchronizer(t *testing.T) {
+	store := middleware.NewMemoryCSRFTokenStore()
+	config := middleware.DefaultCSRFConfig()
+	config.Mode = middleware.CSRFSynchronizer
+	config.Store = store
+	config.SessionID = func(c *gin.Context) string {
+		session, _ := c.Cookie("session")
+		return session
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewCSRFMiddleware(config))
+	router.GET("/csrf", middleware.CSRFTokenHandler)
+	router.POST("/transfer", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "http://bank.example.com"+path, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "/csrf", "", map[string]string{"Cookie": "session=alice"})
+	if len(w.Result().Cookies()) != 0 || store.Len() != 1 {
+		t.Fatalf("Expected the token to be stored server-side, got cookies %v", w.Result().Cookies())
+	}
+	token, _ := store.Get(context.Background(), "alice")
+
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Cookie": "session=alice", "X-CSRF-Token": token}); w.Code != http.StatusOK {
+		t.Errorf("Expected the session token to pass, got %d", w.Code)
+	}
+	if w := request(http.MethodPost, "/transfer", "", map[string]string{"Cookie": "session=bob", "X-CSRF-Token": token}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected another session's token to be rejected, got %d", w.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected synchronizer mode without SessionID to panic")
+		}
+	}()
+	middleware.NewCSRFMiddleware(middleware.CSRFConfig{Mode: middleware.CSRFSynchronizer})
+}
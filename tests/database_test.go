@@ -1,11 +1,21 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/config"
 	"github.com/JorgeSaicoski/microservice-commons/database"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 func TestDatabaseConfigValidation(t *testing.T) {
@@ -90,3 +100,89 @@ func TestHealthChecker(t *testing.T) {
 		t.Error("Expected timestamp to be set")
 	}
 }
+
+type instrumentedOrder struct {
+	ID    uint
+	Email string
+}
+
+func TestQueryInstrumentation(t *testing.T) {
+	// Dry run builds SQL and runs callbacks without a database server
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open dry-run database: %v", err)
+	}
+
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(tracing.Options{Exporter: exporter, SampleRatio: 1})
+
+	err = db.Use(database.NewQueryInstrumentation(database.InstrumentationConfig{
+		SlowQueryThreshold: time.Nanosecond, // every query is slow
+		Tracer:             tracer,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to register instrumentation: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With(slog.String("request_id", "req-db"))
+	ctx := logging.WithLogger(context.Background(), logger)
+	ctx, parent := tracer.Start(ctx, "GET /orders", tracing.SpanKindServer)
+
+	var order instrumentedOrder
+	db.WithContext(ctx).Where("email = ?", "secret@example.com").First(&order)
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected query and parent spans, got %d", len(spans))
+	}
+
+	query := spans[0]
+	if query.Name != "SELECT instrumented_orders" {
+		t.Errorf("Expected span named after operation and table, got %s", query.Name)
+	}
+	if query.ParentSpanID != parent.SpanContext().SpanID {
+		t.Error("Expected query span to be a child of the request span")
+	}
+	if statement, _ := query.Attributes["db.statement"].(string); strings.Contains(statement, "secret@example.com") {
+		t.Errorf("Expected parameter values to be omitted, got %s", statement)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected slow query log line: %v (%s)", err, buf.String())
+	}
+	if record["msg"] != "slow query" || record["request_id"] != "req-db" {
+		t.Errorf("Expected slow query log with request ID, got %v", record)
+	}
+	if strings.Contains(buf.String(), "secret@example.com") {
+		t.Error("Expected parameter values to be omitted from logs")
+	}
+
+	histograms, ok := expvar.Get(database.QueryDurationMetric).(interface{ String() string })
+	if !ok || !strings.Contains(histograms.String(), "select:instrumented_orders") {
+		t.Errorf("Expected latency histogram for select:instrumented_orders")
+	}
+}
+
+func TestQuotaPeriods(t *testing.T) {
+	at := time.Date(2024, time.January, 31, 22, 30, 0, 0, time.FixedZone("UTC-3", -3*60*60))
+
+	// 22:30 at UTC-3 is already February 1st in UTC
+	if got := database.QuotaDaily.Start(at); !got.Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected daily period to start at UTC midnight, got %v", got)
+	}
+	if got := database.QuotaDaily.End(at); !got.Equal(time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected daily period to end the next day, got %v", got)
+	}
+	if got := database.QuotaMonthly.Start(at); !got.Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected monthly period to start on the 1st, got %v", got)
+	}
+	if got := database.QuotaMonthly.End(at); !got.Equal(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected monthly period to end on the next 1st, got %v", got)
+	}
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var created atomic.Int64
+	router := gin.New()
+	router.Use(middleware.IdempotencyMiddleware(middleware.NewMemoryIdempotencyStore()))
+	router.POST("/orders", func(c *gin.Context) {
+		id := created.Add(1)
+		c.Header("Location", fmt.Sprintf("/orders/%d", id))
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("/orders", "key-1", `{"item":"book"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", first.Code)
+	}
+
+	retry := post("/orders", "key-1", `{"item":"book"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response to be replayed, got %d %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("Expected stored headers to be replayed, got %q", retry.Header().Get("Location"))
+	}
+	if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Error("Expected replayed responses to be marked")
+	}
+	if created.Load() != 1 {
+		t.Errorf("Expected the handler to run once, ran %d times", created.Load())
+	}
+
+	if w := post("/orders", "key-1", `{"item":"pen"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 when reusing a key with a different body, got %d", w.Code)
+	}
+
+	post("/orders", "key-2", `{"item":"book"}`)
+	post("/orders", "", `{"item":"book"}`)
+	if created.Load() != 3 {
+		t.Errorf("Expected new and missing keys to reach the handler, got %d calls", created.Load())
+	}
+}
+
+func TestIdempotencyInProgressAndFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var created atomic.Int64
+	store := middleware.NewMemoryIdempotencyStore()
+	block := make(chan struct{})
+	router := gin.New()
+	router.Use(middleware.IdempotencyMiddleware(store))
+	router.POST("/orders", func(c *gin.Context) {
+		<-block
+		c.JSON(http.StatusCreated, gin.H{"id": created.Add(1)})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		created.Add(1)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post("/orders", "slow", `{}`) }()
+
+	deadline := time.Now().Add(time.Second)
+	for store.Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first request to claim the key")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if w := post("/orders", "slow", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the first request is in progress, got %d", w.Code)
+	}
+
+	close(block)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("Expected the first request to succeed, got %d", w.Code)
+	}
+	if w := post("/orders", "slow", `{}`); w.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the completed request to be replayed, got %d", w.Code)
+	}
+
+	before := created.Load()
+	post("/fail", "retry-me", `{}`)
+	post("/fail", "retry-me", `{}`)
+	if created.Load()-before != 2 {
+		t.Error("Expected server errors not to be stored so retries run again")
+	}
+}
+
+func TestIdempotencyScopedToResource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var updated atomic.Int64
+	router := gin.New()
+	router.Use(middleware.IdempotencyMiddleware(middleware.NewMemoryIdempotencyStore()))
+	router.PATCH("/users/:id", func(c *gin.Context) {
+		updated.Add(1)
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+
+	patch := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"active":false}`))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "same-key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	patch("/users/1")
+	second := patch("/users/2")
+	if second.Header().Get(middleware.IdempotentReplayedHeader) == "true" || !strings.Contains(second.Body.String(), `"2"`) {
+		t.Errorf("Expected another resource not to replay user 1's response, got %s", second.Body.String())
+	}
+	patch("/users/2?notify=true")
+	if updated.Load() != 3 {
+		t.Errorf("Expected each resource and query to reach the handler, got %d calls", updated.Load())
+	}
+
+	if w := patch("/users/1"); w.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Error("Expected a retry on the same resource to be replayed")
+	}
+}
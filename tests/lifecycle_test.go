@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/lifecycle"
+	"github.com/JorgeSaicoski/microservice-commons/server"
+)
+
+// recordingHook returns a hook that appends start/stop events to the log
+func recordingHook(name string, priority int, log *[]string, mu *sync.Mutex, dependsOn ...string) lifecycle.Hook {
+	record := func(event string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*log = append(*log, event+":"+name)
+			return nil
+		}
+	}
+
+	return lifecycle.Hook{
+		Name:      name,
+		Priority:  priority,
+		DependsOn: dependsOn,
+		OnStart:   record("start"),
+		OnStop:    record("stop"),
+	}
+}
+
+func TestRunnerStartStopOrder(t *testing.T) {
+	var (
+		log []string
+		mu  sync.Mutex
+	)
+
+	runner := lifecycle.NewRunner(server.DefaultGracefulConfig())
+	runner.Register(
+		recordingHook("http", lifecycle.PriorityHTTP, &log, &mu),
+		recordingHook("worker", lifecycle.PriorityWorker, &log, &mu, "cache"),
+		recordingHook("database", lifecycle.PriorityDatabase, &log, &mu),
+		recordingHook("cache", lifecycle.PriorityHTTP+1, &log, &mu),
+	)
+
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected start error: %v", err)
+	}
+	if err := runner.Stop(context.Background()); err != nil {
+		t.Fatalf("Unexpected stop error: %v", err)
+	}
+
+	expected := []string{
+		"start:database", "start:http", "start:cache", "start:worker",
+		"stop:worker", "stop:cache", "stop:http", "stop:database",
+	}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected order %v, got %v", expected, log)
+	}
+}
+
+func TestRunnerStartFailureStopsStartedHooks(t *testing.T) {
+	var (
+		log []string
+		mu  sync.Mutex
+	)
+
+	failing := lifecycle.Hook{
+		Name:     "broken",
+		Priority: lifecycle.PriorityWorker,
+		OnStart: func(ctx context.Context) error {
+			return errors.New("boom")
+		},
+	}
+
+	runner := lifecycle.NewRunner(server.DefaultGracefulConfig())
+	runner.Register(recordingHook("database", lifecycle.PriorityDatabase, &log, &mu), failing)
+
+	err := runner.Start(context.Background())
+	if err == nil {
+		t.Fatal("Expected start error")
+	}
+
+	var hookErr *lifecycle.HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != "broken" {
+		t.Errorf("Expected HookError for 'broken', got %v", err)
+	}
+
+	if strings.Join(log, ",") != "start:database,stop:database" {
+		t.Errorf("Expected database to be stopped after failure, got %v", log)
+	}
+}
+
+func TestRunnerStopTimeoutAggregatesErrors(t *testing.T) {
+	stuck := func(name string) lifecycle.Hook {
+		return lifecycle.Hook{
+			Name:    name,
+			Timeout: 20 * time.Millisecond,
+			OnStop: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+		}
+	}
+
+	runner := lifecycle.NewRunner(server.DefaultGracefulConfig())
+	runner.Register(stuck("first"), stuck("second"))
+
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected start error: %v", err)
+	}
+
+	start := time.Now()
+	err := runner.Stop(context.Background())
+	if err == nil {
+		t.Fatal("Expected stop error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Expected per-hook timeouts to bound stop duration")
+	}
+	if !strings.Contains(err.Error(), "first") || !strings.Contains(err.Error(), "second") {
+		t.Errorf("Expected errors from both hooks, got %v", err)
+	}
+}
+
+func TestRunnerRejectsDependencyCycle(t *testing.T) {
+	runner := lifecycle.NewRunner(server.DefaultGracefulConfig())
+	runner.Register(
+		lifecycle.Hook{Name: "a", DependsOn: []string{"b"}},
+		lifecycle.Hook{Name: "b", DependsOn: []string{"a"}},
+	)
+
+	if err := runner.Start(context.Background()); err == nil {
+		t.Error("Expected error for dependency cycle")
+	}
+}
@@ -0,0 +1,277 @@
+package test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/server"
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoadSheddingRejectsExcess(t *testing.T) {
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+		Name:          "test_reject",
+		MaxConcurrent: 2,
+		QueueSize:     -1,
+		RetryAfter:    2 * time.Second,
+	})
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	gin.SetMode(gin.TestMode)
+
+	// /slow blocks until release is closed, signalling entered as it starts
+	router := gin.New()
+	router.Use(shedder.Middleware())
+	router.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get("/slow")
+		}()
+		<-entered
+	}
+
+	w := get("/fast")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 at the concurrency limit, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", w.Header().Get("Retry-After"))
+	}
+
+	stats := shedder.Stats()
+	if stats.InFlight != 2 || stats.Rejected != 1 {
+		t.Errorf("Expected 2 in flight and 1 rejected, got %+v", stats)
+	}
+	if check := shedder.HealthChecker()(); check.Status != middleware.HealthStatusDegraded {
+		t.Errorf("Expected degraded health while shedding, got %s", check.Status)
+	}
+
+	published, ok := expvar.Get(middleware.LoadSheddingMetric).(*expvar.Map)
+	if !ok || published.Get("test_reject") == nil {
+		t.Error("Expected stats to be published to expvar")
+	}
+
+	close(release)
+	wg.Wait()
+
+	if w := get("/fast"); w.Code != http.StatusOK {
+		t.Errorf("Expected requests to pass once slots free up, got %d", w.Code)
+	}
+}
+
+func TestLoadSheddingQueue(t *testing.T) {
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+		Name:          "test_queue",
+		MaxConcurrent: 1,
+		QueueSize:     1,
+		QueueTimeout:  time.Second,
+	})
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	gin.SetMode(gin.TestMode)
+
+	// /slow blocks until release is closed, signalling entered as it starts
+	router := gin.New()
+	router.Use(shedder.Middleware())
+	router.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- get("/slow").Code
+		}()
+	}
+	<-entered
+
+	// Wait for the second request to queue
+	deadline := time.Now().Add(time.Second)
+	for shedder.Stats().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a request to be queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if w := get("/fast"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 with a full queue, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("Expected queued request to be served, got %d", code)
+		}
+	}
+}
+
+func TestLoadSheddingRouteLimits(t *testing.T) {
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+		Name:          "test_routes",
+		MaxConcurrent: 10,
+		RouteLimits:   map[string]int{"GET /slow": 1},
+		QueueSize:     -1,
+	})
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	gin.SetMode(gin.TestMode)
+
+	// /slow blocks until release is closed, signalling entered as it starts
+	router := gin.New()
+	router.Use(shedder.Middleware())
+	router.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	done := make(chan struct{})
+	go func() {
+		get("/slow")
+		close(done)
+	}()
+	<-entered
+
+	if w := get("/fast"); w.Code != http.StatusOK {
+		t.Errorf("Expected other routes to be unaffected, got %d", w.Code)
+	}
+
+	// The route limit rejects before the handler runs
+	go func() { <-entered }()
+	if w := get("/slow"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 at the route limit, got %d", w.Code)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestLoadSheddingAdaptiveLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var delay time.Duration
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+		Name:          "test_adaptive",
+		MaxConcurrent: 10,
+		Adaptive: &middleware.AdaptiveLimitConfig{
+			MinLimit:      2,
+			TargetLatency: time.Millisecond,
+		},
+	})
+	router := gin.New()
+	router.Use(shedder.Middleware())
+	router.GET("/work", func(c *gin.Context) {
+		time.Sleep(delay)
+		c.Status(http.StatusOK)
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	delay = 5 * time.Millisecond
+	for i := 0; i < 30; i++ {
+		get("/work")
+	}
+
+	if limit := shedder.Stats().Limit; limit >= 10 || limit < 2 {
+		t.Errorf("Expected slow responses to shrink the limit towards 2, got %d", limit)
+	}
+}
+
+func TestServerLoadSheddingHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	shedding := middleware.DefaultLoadSheddingConfig()
+	shedding.Name = "test_server"
+	shedding.MaxConcurrent = 1
+	shedding.QueueSize = -1
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	srv := server.NewServer(server.ServerOptions{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.0.0",
+		LoadShedding:   &shedding,
+		SetupRoutes: func(router *gin.Engine, cfg *config.Config) {
+			router.GET("/slow", func(c *gin.Context) {
+				entered <- struct{}{}
+				<-release
+			})
+		},
+	})
+	if srv.GetLoadShedder() == nil {
+		t.Fatal("Expected the server to keep its load shedder")
+	}
+	router := srv.GetRouter()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		get("/slow")
+	}()
+	<-entered
+
+	w := get("/health/detailed")
+	var health struct {
+		Status string                            `json:"status"`
+		Checks map[string]middleware.HealthCheck `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatalf("Failed to decode health response: %v", err)
+	}
+	check, ok := health.Checks["load_shedding:test_server"]
+	if !ok || check.Status != middleware.HealthStatusDegraded || health.Status != "degraded" {
+		t.Errorf("Expected a degraded load shedding check at the limit, got %s", w.Body.String())
+	}
+
+	close(release)
+	<-done
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+func TestLoggerFromConfig(t *testing.T) {
+	var buf bytes.Buffer
+
+	cfg := &config.Config{
+		LogLevel:       "warn",
+		LogFormat:      "json",
+		Environment:    "staging",
+		ServiceName:    "log-service",
+		ServiceVersion: "1.2.3",
+	}
+
+	opts := logging.OptionsFromConfig(cfg)
+	opts.Output = &buf
+	logger := logging.New(opts)
+	defer logging.SetLevel(slog.LevelInfo)
+
+	logger.Info("hidden")
+	logger.Warn("visible")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %s", len(lines), buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+
+	if record["service"] != "log-service" || record["version"] != "1.2.3" || record["environment"] != "staging" {
+		t.Errorf("Expected service attributes, got %v", record)
+	}
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	logging.SetLevel(slog.LevelInfo)
+	defer logging.SetLevel(slog.LevelInfo)
+
+	handler := logging.LevelHandler()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/log/level", strings.NewReader(`{"level":"debug"}`))
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if logging.Level() != slog.LevelDebug {
+		t.Errorf("Expected level debug, got %v", logging.Level())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/log/level?level=verbose", nil)
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unknown level, got %d", http.StatusBadRequest, w.Code)
+	}
+}
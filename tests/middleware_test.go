@@ -1,11 +1,15 @@
 package test
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/JorgeSaicoski/microservice-commons/logging"
 	"github.com/JorgeSaicoski/microservice-commons/middleware"
 	"github.com/gin-gonic/gin"
 )
@@ -171,3 +175,207 @@ func TestAuthMiddleware(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
 	}
 }
+
+func TestContextLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	previous := logging.Default()
+	logging.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer logging.SetDefault(previous)
+
+	tokenValidator := func(token string) (map[string]interface{}, error) {
+		return map[string]interface{}{"user_id": "user-42"}, nil
+	}
+
+	router := gin.New()
+	router.Use(middleware.DefaultRequestIDMiddleware())
+	router.Use(middleware.ContextLoggerMiddleware())
+	router.Use(middleware.RequireAuth(tokenValidator))
+	router.GET("/orders/:id", func(c *gin.Context) {
+		// Logs through the request context are correlated too
+		logging.FromContext(c.Request.Context()).Info("loading order")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/7", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Request-ID", "req-123")
+	router.ServeHTTP(w, req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON log line: %v (%s)", err, buf.String())
+	}
+
+	expected := map[string]string{
+		"request_id": "req-123",
+		"user_id":    "user-42",
+		"method":     "GET",
+		"route":      "/orders/:id",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, record[key])
+		}
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(middleware.DefaultRequestIDMiddleware())
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingConfig{
+		Level:       middleware.LogLevelInfo,
+		Format:      middleware.AccessLogJSON,
+		Output:      &buf,
+		SampleRates: map[string]float64{"/health": 0},
+	}))
+	router.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/items/:id", func(c *gin.Context) {
+		c.String(http.StatusNotFound, "missing")
+	})
+
+	// Sampled-out successful request is not logged
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health", nil)
+	router.ServeHTTP(w, req)
+	if buf.Len() != 0 {
+		t.Fatalf("Expected health check to be sampled out, got %s", buf.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/items/9", strings.NewReader(`{"a":1}`))
+	req.Header.Set("X-Request-ID", "req-9")
+	router.ServeHTTP(w, req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON access log line: %v (%s)", err, buf.String())
+	}
+
+	expected := map[string]interface{}{
+		"level":      "WARN",
+		"request_id": "req-9",
+		"route":      "/items/:id",
+		"path":       "/items/9",
+		"status":     float64(404),
+		"bytes_in":   float64(7),
+		"bytes_out":  float64(7),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+}
+
+func TestAccessLogQueryRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, format := range []middleware.AccessLogFormat{middleware.AccessLogJSON, middleware.AccessLogLogfmt, middleware.AccessLogCombined} {
+		var buf bytes.Buffer
+		router := gin.New()
+		router.Use(middleware.NewLoggingMiddleware(middleware.LoggingConfig{
+			Format: format,
+			Output: &buf,
+		}))
+		router.GET("/callback", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/callback?state=xyz&access_token=s3cr3t&api_key=k3y", nil)
+		router.ServeHTTP(w, req)
+
+		line := buf.String()
+		if strings.Contains(line, "s3cr3t") || strings.Contains(line, "k3y") {
+			t.Errorf("%s: expected sensitive query parameters to be redacted, got %s", format, line)
+		}
+		if !strings.Contains(line, "state=xyz") {
+			t.Errorf("%s: expected other query parameters to be kept, got %s", format, line)
+		}
+	}
+}
+
+func TestAccessLogBodyRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bodyConfig := middleware.DefaultBodyLogConfig()
+	bodyConfig.Enabled = true
+	bodyConfig.LogHeaders = true
+	bodyConfig.ExcludeRoutes = []string{"/upload"}
+
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(middleware.NewLoggingMiddleware(middleware.LoggingConfig{
+		Format: middleware.AccessLogJSON,
+		Output: &buf,
+		Body:   bodyConfig,
+	}))
+	router.POST("/login", func(c *gin.Context) {
+		var payload map[string]interface{}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user": payload["user"], "access_token": "abc"})
+	})
+	router.POST("/upload", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/login",
+		strings.NewReader(`{"user":"ana","password":"hunter2","card":"4111 1111 1111 1111"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected handler to still read the body, got status %d", w.Code)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON access log line: %v (%s)", err, buf.String())
+	}
+
+	requestBody, _ := record["request_body"].(string)
+	if strings.Contains(requestBody, "hunter2") || strings.Contains(requestBody, "4111") {
+		t.Errorf("Expected sensitive request fields to be redacted, got %s", requestBody)
+	}
+	if !strings.Contains(requestBody, `"user":"ana"`) {
+		t.Errorf("Expected non-sensitive fields to be kept, got %s", requestBody)
+	}
+
+	responseBody, _ := record["response_body"].(string)
+	if strings.Contains(responseBody, "abc") {
+		t.Errorf("Expected token in response to be redacted, got %s", responseBody)
+	}
+
+	headers, _ := record["request_headers"].(map[string]interface{})
+	if headers["Authorization"] != "[REDACTED]" {
+		t.Errorf("Expected Authorization header to be redacted, got %v", headers["Authorization"])
+	}
+
+	// Excluded routes are logged without bodies
+	buf.Reset()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/upload", strings.NewReader(`{"file":"data"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	record = nil
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON access log line: %v (%s)", err, buf.String())
+	}
+	if _, exists := record["request_body"]; exists {
+		t.Errorf("Expected no body capture on excluded route, got %v", record["request_body"])
+	}
+}
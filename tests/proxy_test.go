@@ -0,0 +1,148 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// proxyResult is what a handler behind the proxy headers middleware sees
+type proxyResult struct {
+	ClientIP string `json:"client_ip"`
+	URL      string `json:"url"`
+}
+
+func TestProxyHeadersXForwarded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(middleware.ProxyHeadersMiddleware([]string{"10.0.0.0/8"}))
+	router.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, proxyResult{
+			ClientIP: c.ClientIP(),
+			URL:      responses.AbsoluteURL(c, c.Request.URL.Path),
+		})
+	})
+
+	request := func(remoteAddr string) proxyResult {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://internal:8080/items", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.2, 10.0.0.5")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "api.example.com")
+		router.ServeHTTP(w, req)
+
+		var result proxyResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+		}
+		return result
+	}
+
+	result := request("10.0.0.1:4000")
+	if result.ClientIP != "198.51.100.2" {
+		t.Errorf("Expected the first untrusted hop as client, got %s", result.ClientIP)
+	}
+	if result.URL != "https://api.example.com/items" {
+		t.Errorf("Expected the forwarded URL, got %s", result.URL)
+	}
+
+	result = request("192.0.2.1:4000")
+	if result.ClientIP != "192.0.2.1" || result.URL != "http://internal:8080/items" {
+		t.Errorf("Expected headers from untrusted peers to be ignored, got %+v", result)
+	}
+}
+
+func TestProxyHeadersForwarded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(middleware.ProxyHeadersMiddleware([]string{"10.0.0.1"}))
+	router.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, proxyResult{
+			ClientIP: c.ClientIP(),
+			URL:      responses.AbsoluteURL(c, c.Request.URL.Path),
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://internal:8080/items", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	req.Header.Set("Forwarded", `for="[2001:db8:cafe::17]:4711";proto=https;host=shop.example.com, for=10.0.0.1`)
+	req.Header.Set("X-Forwarded-For", "192.0.2.99")
+	router.ServeHTTP(w, req)
+
+	var result proxyResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+	}
+	if result.ClientIP != "2001:db8:cafe::17" {
+		t.Errorf("Expected Forwarded to take precedence, got %s", result.ClientIP)
+	}
+	if result.URL != "https://shop.example.com/items" {
+		t.Errorf("Expected the forwarded URL, got %s", result.URL)
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewIPFilterMiddleware(middleware.IPFilterConfig{
+		Allow: []string{"10.0.0.0/8", "192.0.2.10"},
+		Deny:  []string{"10.1.0.0/16"},
+	}))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := map[string]int{
+		"10.2.3.4:1000":   http.StatusOK,
+		"192.0.2.10:1000": http.StatusOK,
+		"10.1.2.3:1000":   http.StatusForbidden,
+		"192.0.2.11:1000": http.StatusForbidden,
+	}
+	for remoteAddr, expected := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.RemoteAddr = remoteAddr
+		router.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("%s: expected %d, got %d", remoteAddr, expected, w.Code)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid CIDR to panic")
+		}
+	}()
+	middleware.DenyIPs("10.0.0.0/33")
+}
+
+func TestTrustedProxiesConfig(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 172.16.0.1")
+	cfg := config.LoadFromEnv()
+	if len(cfg.ServerConfig.TrustedProxies) != 2 {
+		t.Errorf("Expected trusted proxies from env, got %v", cfg.ServerConfig.TrustedProxies)
+	}
+	if err := cfg.ServerConfig.Validate(); err != nil {
+		t.Errorf("Expected trusted proxies to be valid, got %v", err)
+	}
+
+	cfg.ServerConfig.TrustedProxies = []string{"not-an-ip"}
+	if cfg.ServerConfig.Validate() == nil {
+		t.Error("Expected an invalid trusted proxy to fail validation")
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	config := middleware.DefaultRateLimitConfig()
+	config.Limit = middleware.RateLimit{Requests: 3, Window: time.Minute}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewRateLimitMiddleware(config))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method, path, clientIP string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = clientIP + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		w := call(http.MethodGet, "/items", "10.0.0.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d to pass, got %d", i+1, w.Code)
+		}
+		if got := w.Header().Get(middleware.RateLimitRemainingHeader); got != fmt.Sprint(2-i) {
+			t.Errorf("Expected %d remaining, got %s", 2-i, got)
+		}
+	}
+
+	w := call(http.MethodGet, "/items", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the bucket is empty, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "20" {
+		t.Errorf("Expected Retry-After of one refill interval, got %q", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get(middleware.RateLimitLimitHeader) != "3" {
+		t.Errorf("Expected RateLimit-Limit 3, got %q", w.Header().Get(middleware.RateLimitLimitHeader))
+	}
+
+	if w := call(http.MethodGet, "/items", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("Expected other clients to have their own limit, got %d", w.Code)
+	}
+}
+
+func TestRateLimitSlidingWindowRouteOverride(t *testing.T) {
+	config := middleware.DefaultRateLimitConfig()
+	config.Algorithm = middleware.SlidingWindow
+	config.Limit = middleware.RateLimit{Requests: 100, Window: time.Minute}
+	config.Routes = map[string]middleware.RateLimit{
+		"POST /login": {Requests: 2, Window: time.Hour},
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewRateLimitMiddleware(config))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method, path, clientIP string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = clientIP + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := call(http.MethodPost, "/login", "10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("Expected login %d to pass, got %d", i+1, w.Code)
+		}
+	}
+
+	w := call(http.MethodPost, "/login", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected login route limit to apply, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+
+	w = call(http.MethodGet, "/items", "10.0.0.1")
+	if w.Code != http.StatusOK || w.Header().Get(middleware.RateLimitLimitHeader) != "100" {
+		t.Errorf("Expected default limit on other routes, got %d limit %s", w.Code, w.Header().Get(middleware.RateLimitLimitHeader))
+	}
+}
+
+func TestMemoryRateLimitStoreEviction(t *testing.T) {
+	store := middleware.NewMemoryRateLimitStore(middleware.MemoryRateLimitStoreConfig{MaxKeys: 2})
+	limit := middleware.RateLimit{Requests: 1, Window: time.Minute, Algorithm: middleware.TokenBucket}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := store.Take(context.Background(), key, limit); err != nil {
+			t.Fatalf("Expected take to succeed, got %v", err)
+		}
+	}
+
+	if store.Len() != 2 {
+		t.Errorf("Expected store to hold 2 keys, got %d", store.Len())
+	}
+
+	// "a" was evicted, so it starts with a full bucket again
+	result, _ := store.Take(context.Background(), "a", limit)
+	if !result.Allowed {
+		t.Error("Expected evicted key to start fresh")
+	}
+}
@@ -0,0 +1,202 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/client"
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/resilience"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	changes := make(chan resilience.StateChange, 4)
+	breaker := resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		Name:                 "orders",
+		WindowSize:           4,
+		MinimumCalls:         4,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          20 * time.Millisecond,
+		HalfOpenMaxCalls:     1,
+		OnStateChange:        func(change resilience.StateChange) { changes <- change },
+	})
+
+	failing := func(context.Context) error { return errors.New("boom") }
+	succeeding := func(context.Context) error { return nil }
+
+	for _, fn := range []func(context.Context) error{succeeding, succeeding, failing, failing} {
+		_ = breaker.Execute(context.Background(), fn)
+	}
+
+	if breaker.State() != resilience.StateOpen {
+		t.Fatalf("Expected breaker to open at 50%% failures, got %s", breaker.State())
+	}
+
+	var called bool
+	err := breaker.Execute(context.Background(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, resilience.ErrCircuitOpen) || called {
+		t.Fatalf("Expected open breaker to reject the call, got %v", err)
+	}
+
+	check := breaker.HealthChecker()()
+	if check.Status != middleware.HealthStatusDegraded {
+		t.Errorf("Expected degraded health while open, got %s", check.Status)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := breaker.Execute(context.Background(), succeeding); err != nil {
+		t.Fatalf("Expected trial call to be allowed, got %v", err)
+	}
+	if breaker.State() != resilience.StateClosed {
+		t.Errorf("Expected breaker to close after a successful trial, got %s", breaker.State())
+	}
+
+	for _, want := range []resilience.State{resilience.StateOpen, resilience.StateHalfOpen, resilience.StateClosed} {
+		select {
+		case change := <-changes:
+			if change.To != want {
+				t.Errorf("Expected transition to %s, got %s", want, change.To)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected state change event to %s", want)
+		}
+	}
+}
+
+func TestCircuitBreakerSlowCalls(t *testing.T) {
+	breaker := resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		Name:                  "slow",
+		WindowSize:            2,
+		MinimumCalls:          2,
+		SlowCallDuration:      5 * time.Millisecond,
+		SlowCallRateThreshold: 1,
+	})
+
+	for i := 0; i < 2; i++ {
+		_ = breaker.Execute(context.Background(), func(context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+	}
+
+	if breaker.State() != resilience.StateOpen {
+		t.Errorf("Expected slow calls to open the breaker, got %s", breaker.State())
+	}
+}
+
+func TestBulkheadLimitsConcurrency(t *testing.T) {
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{Name: "reports", MaxConcurrent: 1})
+
+	release, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected first slot, got %v", err)
+	}
+
+	err = bulkhead.Execute(context.Background(), func(context.Context) error { return nil })
+	if !errors.Is(err, resilience.ErrBulkheadFull) {
+		t.Errorf("Expected ErrBulkheadFull, got %v", err)
+	}
+	if check := bulkhead.HealthChecker()(); check.Status != middleware.HealthStatusDegraded {
+		t.Errorf("Expected degraded health while full, got %s", check.Status)
+	}
+
+	release()
+	if err := bulkhead.Execute(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Errorf("Expected call after release to succeed, got %v", err)
+	}
+}
+
+func TestCircuitBreakerWrapsClient(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	breaker := resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		Name:         "upstream",
+		WindowSize:   2,
+		MinimumCalls: 2,
+	})
+
+	config := client.DefaultConfig()
+	config.BaseURL = upstream.URL
+	config.InitialBackoff = time.Millisecond
+	config.RetryStatuses = []int{http.StatusInternalServerError}
+	config.Transport = resilience.NewTransport(resilience.TransportConfig{Breaker: breaker})
+	c := client.New(config)
+
+	err := c.Get(context.Background(), "/", nil)
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("Expected retries to stop at the open circuit, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 calls before the circuit opened, got %d", calls.Load())
+	}
+}
+
+func TestTransportIgnoresCallerCancellation(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	breaker := resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		Name:         "upstream",
+		WindowSize:   2,
+		MinimumCalls: 2,
+	})
+	httpClient := &http.Client{Transport: resilience.NewTransport(resilience.TransportConfig{Breaker: breaker})}
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+		if _, err := httpClient.Do(req); err == nil {
+			t.Fatal("Expected the call to time out")
+		}
+		cancel()
+	}
+
+	if breaker.State() != resilience.StateClosed {
+		t.Errorf("Expected the caller's own deadlines not to open the circuit, got %s", breaker.State())
+	}
+}
+
+func TestTransportBulkheadCoversBody(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("report"))
+	}))
+	defer upstream.Close()
+
+	bulkhead := resilience.NewBulkhead(resilience.BulkheadConfig{Name: "reports", MaxConcurrent: 1})
+	httpClient := &http.Client{Transport: resilience.NewTransport(resilience.TransportConfig{Bulkhead: bulkhead})}
+
+	resp, err := httpClient.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("Expected first call to succeed, got %v", err)
+	}
+	if _, err := httpClient.Get(upstream.URL); !errors.Is(err, resilience.ErrBulkheadFull) {
+		t.Errorf("Expected the slot to be held while the body is open, got %v", err)
+	}
+
+	resp.Body.Close()
+	resp, err = httpClient.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("Expected the slot to be freed once the body is closed, got %v", err)
+	}
+	resp.Body.Close()
+}
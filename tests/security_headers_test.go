@@ -0,0 +1,90 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSPBuilder(t *testing.T) {
+	csp := middleware.NewCSP().
+		DefaultSrc(middleware.CSPSelf).
+		ScriptSrc(middleware.CSPSelf, "https://cdn.example.com").
+		ImgSrc(middleware.CSPSelf, middleware.CSPData).
+		DefaultSrc(middleware.CSPNone).
+		UpgradeInsecureRequests()
+
+	expected := "default-src 'none'; script-src 'self' https://cdn.example.com; img-src 'self' data:; upgrade-insecure-requests"
+	if got := csp.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if csp.UsesNonce() {
+		t.Error("Expected no nonce")
+	}
+
+	csp.ScriptSrc(middleware.CSPNonce, middleware.CSPStrictDynamic)
+	if got := csp.Build("abc"); !strings.Contains(got, "script-src 'nonce-abc' 'strict-dynamic'") {
+		t.Errorf("Expected the nonce to be filled in, got %q", got)
+	}
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ProductionSecurityHeadersMiddleware())
+	router.GET("/page", func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.GetCSPNonce(c))
+	})
+
+	nonces := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+
+		expected := map[string]string{
+			"Strict-Transport-Security":    "max-age=63072000; includeSubDomains; preload",
+			"X-Content-Type-Options":       "nosniff",
+			"X-Frame-Options":              "DENY",
+			"Referrer-Policy":              "strict-origin-when-cross-origin",
+			"Cross-Origin-Opener-Policy":   "same-origin",
+			"Cross-Origin-Embedder-Policy": "require-corp",
+		}
+		for name, value := range expected {
+			if got := w.Header().Get(name); got != value {
+				t.Errorf("Expected %s %q, got %q", name, value, got)
+			}
+		}
+
+		nonce := w.Body.String()
+		if nonce == "" || !strings.Contains(w.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'") {
+			t.Errorf("Expected the policy to carry the handler's nonce, got %q", w.Header().Get("Content-Security-Policy"))
+		}
+		nonces[nonce] = true
+	}
+	if len(nonces) != 2 {
+		t.Error("Expected a new nonce per request")
+	}
+}
+
+func TestDevelopmentSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.DevelopmentSecurityHeadersMiddleware())
+	router.GET("/page", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+
+	if w.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("Expected no HSTS in development")
+	}
+	if w.Header().Get("Content-Security-Policy") != "" || w.Header().Get("Content-Security-Policy-Report-Only") == "" {
+		t.Error("Expected a report-only policy in development")
+	}
+}
@@ -1,9 +1,23 @@
 package test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/JorgeSaicoski/microservice-commons/config"
 	"github.com/JorgeSaicoski/microservice-commons/server"
@@ -35,6 +49,50 @@ func TestNewServer(t *testing.T) {
 	}
 }
 
+func TestServerNegativeTimeoutDisables(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("SERVER_WRITE_TIMEOUT", "-1s")
+
+	srv := server.NewServer(server.ServerOptions{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.0.0",
+		SetupRoutes:    func(router *gin.Engine, cfg *config.Config) {},
+	})
+
+	httpServer := srv.GetHTTPServer()
+	if httpServer.WriteTimeout != 0 {
+		t.Errorf("Expected a negative write timeout to disable it, got %v", httpServer.WriteTimeout)
+	}
+	if httpServer.ReadTimeout != config.DefaultReadTimeout {
+		t.Errorf("Expected the default read timeout, got %v", httpServer.ReadTimeout)
+	}
+}
+
+func TestServerHandBuiltConfigKeepsDefaultTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A configuration whose server settings were not loaded from the
+	// environment
+	cfg := config.LoadFromEnv()
+	cfg.ServerConfig = config.ServerConfig{}
+
+	srv := server.NewServer(server.ServerOptions{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.0.0",
+		Config:         cfg,
+		SetupRoutes:    func(router *gin.Engine, cfg *config.Config) {},
+	})
+
+	httpServer := srv.GetHTTPServer()
+	if httpServer.ReadTimeout != config.DefaultReadTimeout ||
+		httpServer.ReadHeaderTimeout != config.DefaultReadHeaderTimeout ||
+		httpServer.WriteTimeout != config.DefaultWriteTimeout ||
+		httpServer.IdleTimeout != config.DefaultIdleTimeout {
+		t.Errorf("Expected default timeouts for a zero ServerConfig, got read %v, header %v, write %v, idle %v",
+			httpServer.ReadTimeout, httpServer.ReadHeaderTimeout, httpServer.WriteTimeout, httpServer.IdleTimeout)
+	}
+}
+
 func TestServerOptions_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -150,3 +208,224 @@ func TestServerWithCustomConfig(t *testing.T) {
 		t.Errorf("Expected port '9999', got %s", config.Port)
 	}
 }
+
+func TestReadinessReportsDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	options := server.ServerOptions{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.0.0",
+		SetupRoutes:    func(router *gin.Engine, cfg *config.Config) {},
+	}
+
+	srv := server.NewServer(options)
+	router := srv.GetRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ready", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d before draining, got %d", http.StatusOK, w.Code)
+	}
+
+	srv.GetRequestTracker().StartDraining()
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/ready", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d while draining, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	if w.Header().Get("Connection") != "close" {
+		t.Error("Expected Connection: close while draining")
+	}
+}
+
+func TestShutdownWaitsForDrainDelay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	options := server.ServerOptions{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.0.0",
+		SetupRoutes:    func(router *gin.Engine, cfg *config.Config) {},
+		GracefulShutdown: server.GracefulShutdownConfig{
+			Timeout:       time.Second,
+			SignalTimeout: 50 * time.Millisecond,
+		},
+	}
+
+	srv := server.NewServer(options)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go srv.Serve(ln)
+
+	start := time.Now()
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Expected shutdown to wait for the drain delay")
+	}
+
+	if !srv.GetRequestTracker().IsDraining() {
+		t.Error("Expected tracker to be draining after shutdown")
+	}
+}
+
+// writeSelfSignedCert writes a self-signed certificate for the given common name
+func writeSelfSignedCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+}
+
+func TestShutdownRunsEveryHookAfterForcedShutdown(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go httpServer.Serve(ln)
+	go http.Get("http://" + ln.Addr().String())
+	<-started
+
+	hookErr := errors.New("flush failed")
+	var ran []string
+	manager := server.NewShutdownManager(httpServer, server.GracefulShutdownConfig{}).
+		RegisterOnShutdown(func(context.Context) error {
+			ran = append(ran, "tracer")
+			return hookErr
+		}).
+		RegisterOnShutdown(func(context.Context) error {
+			ran = append(ran, "admin")
+			return nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = manager.Shutdown(ctx)
+
+	if len(ran) != 2 {
+		t.Errorf("Expected every hook to run despite the forced shutdown, ran %v", ran)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, hookErr) {
+		t.Errorf("Expected the server and hook errors to be joined, got %v", err)
+	}
+}
+
+func TestCertReloaderPicksUpNewCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeSelfSignedCert(t, certFile, keyFile, "first")
+
+	reloader, err := server.NewCertReloader(certFile, keyFile, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	cert, _ := reloader.GetCertificate(nil)
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	if leaf.Subject.CommonName != "first" {
+		t.Fatalf("Expected first certificate, got %s", leaf.Subject.CommonName)
+	}
+
+	writeSelfSignedCert(t, certFile, keyFile, "second")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	time.Sleep(5 * time.Millisecond)
+
+	cert, _ = reloader.GetCertificate(nil)
+	leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	if leaf.Subject.CommonName != "second" {
+		t.Errorf("Expected reloaded certificate, got %s", leaf.Subject.CommonName)
+	}
+}
+
+func TestAdminServerEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.LoadWithServiceInfo("admin-service", "1.0.0")
+	cfg.ServerConfig.AdminPort = "9901"
+	cfg.DatabaseConfig.Password = "super-secret"
+
+	options := server.ServerOptions{
+		ServiceName:    "admin-service",
+		ServiceVersion: "1.0.0",
+		Config:         cfg,
+		SetupRoutes: func(router *gin.Engine, cfg *config.Config) {
+			router.GET("/widgets", func(c *gin.Context) {})
+		},
+	}
+
+	srv := server.NewServer(options)
+	admin := srv.GetAdminServer()
+	if admin == nil {
+		t.Fatal("Expected admin server to be created")
+	}
+
+	// Route table lists public routes
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/routes", nil)
+	admin.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/widgets") {
+		t.Errorf("Expected route table to contain /widgets, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Configuration is redacted
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/config", nil)
+	admin.GetRouter().ServeHTTP(w, req)
+
+	if strings.Contains(w.Body.String(), "super-secret") {
+		t.Error("Expected database password to be redacted")
+	}
+
+	// Profiling is not exposed on the public router
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/debug/pprof/", nil)
+	srv.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected pprof to be absent from public router, got %d", w.Code)
+	}
+}
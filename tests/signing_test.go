@@ -0,0 +1,190 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/signing"
+	"github.com/gin-gonic/gin"
+)
+
+func signingKey(id string) signing.Key {
+	return signing.Key{ID: id, Secret: bytes.Repeat([]byte(id[:1]), signing.MinSecretBytes)}
+}
+
+func authErrorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+	}
+	return response.Code
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	keys, err := signing.NewKeyring(signingKey("k1"))
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.SignatureMiddleware(keys))
+	router.POST("/webhooks/orders", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"key_id": middleware.GetSignatureKeyID(c), "body": string(body)})
+	})
+
+	signedRequest := func(key signing.Key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/orders?b=2&a=1", strings.NewReader(body))
+		if err := signing.Sign(req, []byte(body), key); err != nil {
+			t.Fatalf("Failed to sign request: %v", err)
+		}
+		return req
+	}
+
+	req := signedRequest(signingKey("k1"), `{"order":1}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"key_id":"k1"`) || !strings.Contains(w.Body.String(), `order`) {
+		t.Fatalf("Expected a signed request to pass with its body, got %d %s", w.Code, w.Body.String())
+	}
+
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"order":1}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, replay)
+	if w.Code != http.StatusUnauthorized || authErrorCode(t, w) != "replayed_signature" {
+		t.Errorf("Expected a replay to be rejected, got %d %s", w.Code, w.Body.String())
+	}
+
+	tampered := signedRequest(signingKey("k1"), `{"order":1}`)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"order":2}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tampered)
+	if authErrorCode(t, w) != "invalid_signature" {
+		t.Errorf("Expected a tampered body to be rejected, got %s", w.Body.String())
+	}
+
+	stale := signedRequest(signingKey("k1"), "")
+	stale.Header.Set(signing.HeaderTimestamp, strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, stale)
+	if authErrorCode(t, w) != "stale_signature" {
+		t.Errorf("Expected a stale timestamp to be rejected, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/orders", nil))
+	if authErrorCode(t, w) != "missing_signature" {
+		t.Errorf("Expected an unsigned request to be rejected, got %s", w.Body.String())
+	}
+}
+
+func TestSignatureKeyRotation(t *testing.T) {
+	keys, _ := signing.NewKeyring(signingKey("old"))
+	if err := keys.Add(signingKey("new")); err != nil {
+		t.Fatalf("Failed to add key: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.SignatureMiddleware(keys))
+	router.POST("/webhooks/orders", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"key_id": middleware.GetSignatureKeyID(c), "body": string(body)})
+	})
+
+	signedRequest := func(key signing.Key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/orders?b=2&a=1", strings.NewReader(body))
+		if err := signing.Sign(req, []byte(body), key); err != nil {
+			t.Fatalf("Failed to sign request: %v", err)
+		}
+		return req
+	}
+
+	for _, key := range []signing.Key{signingKey("old"), signingKey("new")} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(key, "{}"))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected key %s to be active, got %d", key.ID, w.Code)
+		}
+	}
+
+	if keys.Remove("old") == nil {
+		t.Error("Expected the primary key not to be removable")
+	}
+	if err := keys.SetPrimary("new"); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	if err := keys.Remove("old"); err != nil {
+		t.Fatalf("Failed to retire key: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(signingKey("old"), "{}"))
+	if authErrorCode(t, w) != "unknown_signing_key" {
+		t.Errorf("Expected a retired key to be rejected, got %s", w.Body.String())
+	}
+
+	if _, err := signing.NewKeyring(signing.Key{ID: "short", Secret: []byte("secret")}); err == nil {
+		t.Error("Expected a short secret to be rejected")
+	}
+}
+
+func TestSigningTransport(t *testing.T) {
+	keys, _ := signing.NewKeyring(signingKey("svc"))
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.SignatureMiddleware(keys))
+	router.POST("/webhooks/orders", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"key_id": middleware.GetSignatureKeyID(c), "body": string(body)})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := &http.Client{Transport: signing.NewTransport(signing.TransportConfig{Keys: keys})}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(server.URL+"/webhooks/orders?a=1", "application/json", strings.NewReader(`{"order":3}`))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "order") {
+			t.Errorf("Expected the signed request to pass, got %d %s", resp.StatusCode, body)
+		}
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("s"), signing.MinSecretBytes))
+	keys, err := signing.ParseKeys("k2:" + secret + ", k1:" + secret)
+	if err != nil || len(keys) != 2 || keys[0].ID != "k2" {
+		t.Fatalf("Expected two keys, got %v, %v", keys, err)
+	}
+	ring, err := signing.NewKeyring(keys...)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	if primary, _ := ring.Primary(); primary.ID != "k2" {
+		t.Errorf("Expected the first key to be primary, got %s", primary.ID)
+	}
+
+	if _, err := signing.ParseKeys("k1"); err == nil {
+		t.Error("Expected a key without a secret to be rejected")
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/responses"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	config := middleware.DefaultTimeoutConfig()
+	config.Timeout = 20 * time.Millisecond
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.DefaultRequestIDMiddleware())
+	router.Use(middleware.NewTimeoutMiddleware(config))
+	// Respects cancellation, like a database call using the request context
+	router.GET("/wait", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			responses.HandleError(c, c.Request.Context().Err())
+		case <-time.After(time.Second):
+			c.Status(http.StatusOK)
+		}
+	})
+	// Ignores cancellation and writes late
+	router.GET("/stubborn", func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "yes")
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/wait", "/stubborn"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Fatalf("%s: expected 504, got %d", path, w.Code)
+		}
+
+		var body responses.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: expected a single JSON error body, got %q", path, w.Body.String())
+		}
+		if body.Code != responses.ErrCodeGatewayTimeout {
+			t.Errorf("%s: expected gateway_timeout code, got %q", path, body.Code)
+		}
+		if body.RequestID == "" || body.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+			t.Errorf("%s: expected the request ID in the body, got %q", path, body.RequestID)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get("X-Handler") != "yes" {
+		t.Errorf("Expected fast handler response to pass through, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestTimeoutMiddlewareRoutes(t *testing.T) {
+	config := middleware.DefaultTimeoutConfig()
+	config.Timeout = 20 * time.Millisecond
+	config.StatusCode = http.StatusServiceUnavailable
+	config.Routes = map[string]time.Duration{"GET /stubborn": 0}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.DefaultRequestIDMiddleware())
+	router.Use(middleware.NewTimeoutMiddleware(config))
+	// Respects cancellation, like a database call using the request context
+	router.GET("/wait", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			responses.HandleError(c, c.Request.Context().Err())
+		case <-time.After(time.Second):
+			c.Status(http.StatusOK)
+		}
+	})
+	// Ignores cancellation and writes late
+	router.GET("/stubborn", func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stubborn", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the route override to disable the timeout, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wait", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected configured 503 status, got %d", w.Code)
+	}
+}
+
+func TestTimeoutMiddlewarePanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.Use(middleware.TimeoutMiddleware(time.Second))
+	router.GET("/panic", func(c *gin.Context) {
+		panic(errors.New("boom"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected handler panics to reach recovery, got %d", w.Code)
+	}
+}
@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JorgeSaicoski/microservice-commons/middleware"
+	"github.com/JorgeSaicoski/microservice-commons/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTracingTraceparentRoundTrip(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := tracing.ParseTraceparent(value)
+	if err != nil {
+		t.Fatalf("Expected valid traceparent, got %v", err)
+	}
+	if !sc.IsSampled() {
+		t.Error("Expected sampled flag to be set")
+	}
+	if got := tracing.FormatTraceparent(sc); got != value {
+		t.Errorf("Expected %s, got %s", value, got)
+	}
+
+	invalid := []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+	}
+	for _, value := range invalid {
+		if _, err := tracing.ParseTraceparent(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestTracingMiddlewareContinuesTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(tracing.Options{ServiceName: "orders", Exporter: exporter, SampleRatio: 1})
+
+	router := gin.New()
+	router.Use(middleware.NewTracingMiddleware(middleware.TracingConfig{Tracer: tracer}))
+	router.Use(middleware.DefaultRequestIDMiddleware())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		_, child := tracer.Start(c.Request.Context(), "load order", tracing.SpanKindInternal)
+		child.End()
+
+		c.JSON(http.StatusOK, gin.H{"request_id": middleware.MustGetRequestID(c)})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(errors.New("boom"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=1")
+	router.ServeHTTP(w, req)
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	child, server := spans[0], spans[1]
+	if server.Name != "GET /orders/:id" {
+		t.Errorf("Expected span named after route template, got %s", server.Name)
+	}
+	if server.Kind != tracing.SpanKindServer {
+		t.Errorf("Expected server span kind, got %d", server.Kind)
+	}
+	if server.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected incoming trace ID to be continued, got %s", server.SpanContext.TraceID)
+	}
+	if server.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected remote parent span ID, got %s", server.ParentSpanID)
+	}
+	if server.SpanContext.TraceState != "vendor=1" {
+		t.Errorf("Expected tracestate to be propagated, got %q", server.SpanContext.TraceState)
+	}
+	if child.ParentSpanID != server.SpanContext.SpanID {
+		t.Error("Expected child span to be parented to the server span")
+	}
+	if server.Attributes["http.response.status_code"] != http.StatusOK {
+		t.Errorf("Expected status attribute, got %v", server.Attributes["http.response.status_code"])
+	}
+
+	// The request ID falls back to the trace ID
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if body["request_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected request ID to reuse the trace ID, got %s", body["request_id"])
+	}
+
+	exporter.Reset()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/fail", nil)
+	router.ServeHTTP(w, req)
+
+	spans = exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status != tracing.StatusError {
+		t.Errorf("Expected error status, got %d", spans[0].Status)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Errorf("Expected recorded error event, got %v", spans[0].Events)
+	}
+	if spans[0].ParentSpanID.IsValid() {
+		t.Error("Expected a root span without incoming traceparent")
+	}
+}
+
+func TestTracingOTLPExporter(t *testing.T) {
+	var received map[string]interface{}
+	var authHeader string
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("Expected /v1/traces, got %s", r.URL.Path)
+		}
+		authHeader = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(tracing.OTLPConfig{
+		Endpoint:    collector.URL,
+		Headers:     map[string]string{"Authorization": "Bearer collector"},
+		ServiceName: "orders",
+	})
+	tracer := tracing.NewTracer(tracing.Options{
+		ServiceName: "orders",
+		Exporter:    tracing.NewBatchExporter(exporter, tracing.BatchConfig{}),
+		SampleRatio: 1,
+	})
+
+	_, span := tracer.Start(context.Background(), "work", tracing.SpanKindInternal)
+	span.SetAttribute("items", 3)
+	span.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected clean shutdown, got %v", err)
+	}
+
+	if authHeader != "Bearer collector" {
+		t.Errorf("Expected configured headers to be sent, got %q", authHeader)
+	}
+
+	resourceSpans, _ := received["resourceSpans"].([]interface{})
+	if len(resourceSpans) != 1 {
+		t.Fatalf("Expected one resourceSpans entry, got %v", received)
+	}
+	scopeSpans := resourceSpans[0].(map[string]interface{})["scopeSpans"].([]interface{})
+	spans := scopeSpans[0].(map[string]interface{})["spans"].([]interface{})
+	exported := spans[0].(map[string]interface{})
+
+	if exported["name"] != "work" {
+		t.Errorf("Expected span name work, got %v", exported["name"])
+	}
+	if exported["traceId"] != span.SpanContext().TraceID.String() {
+		t.Errorf("Expected hex trace ID, got %v", exported["traceId"])
+	}
+}
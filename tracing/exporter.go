@@ -0,0 +1,210 @@
+// tracing/exporter.go
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+// Exporter sends finished spans to a tracing backend
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter keeps finished spans in memory, for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter creates an empty in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpans stores the spans
+func (e *InMemoryExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Shutdown is a no-op
+func (e *InMemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Spans returns a copy of the exported spans in the order they ended
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset discards all stored spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// BatchConfig holds configuration for batching exports
+type BatchConfig struct {
+	MaxBatchSize int           // Spans sent per export (default: 512)
+	MaxQueueSize int           // Spans buffered before new ones are dropped (default: 2048)
+	BatchTimeout time.Duration // Maximum delay before a partial batch is sent (default: 5s)
+}
+
+// DefaultBatchConfig returns default batching configuration
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxBatchSize: 512,
+		MaxQueueSize: 2048,
+		BatchTimeout: 5 * time.Second,
+	}
+}
+
+// BatchExporter queues spans and exports them in batches from a
+// background goroutine, so ending a span never blocks on the network
+type BatchExporter struct {
+	exporter Exporter
+	config   BatchConfig
+	queue    chan SpanData
+	flush    chan chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	dropped  atomic.Int64
+}
+
+// NewBatchExporter wraps exporter with batching, using defaults for zero values
+func NewBatchExporter(exporter Exporter, config BatchConfig) *BatchExporter {
+	defaults := DefaultBatchConfig()
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaults.MaxBatchSize
+	}
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = defaults.MaxQueueSize
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = defaults.BatchTimeout
+	}
+
+	b := &BatchExporter{
+		exporter: exporter,
+		config:   config,
+		queue:    make(chan SpanData, config.MaxQueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+// ExportSpans queues spans for export, dropping them if the queue is full
+func (b *BatchExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	for _, span := range spans {
+		select {
+		case <-b.done:
+			return nil
+		case b.queue <- span:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of spans dropped because the queue was full
+func (b *BatchExporter) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// ForceFlush exports all queued spans
+func (b *BatchExporter) ForceFlush(ctx context.Context) error {
+	ack := make(chan struct{})
+
+	select {
+	case b.flush <- ack:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown flushes queued spans, stops the background goroutine and shuts
+// down the wrapped exporter
+func (b *BatchExporter) Shutdown(ctx context.Context) error {
+	if err := b.ForceFlush(ctx); err != nil {
+		return err
+	}
+
+	b.stopOnce.Do(func() { close(b.done) })
+	return b.exporter.Shutdown(ctx)
+}
+
+// run collects spans into batches until shutdown
+func (b *BatchExporter) run() {
+	ticker := time.NewTicker(b.config.BatchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, b.config.MaxBatchSize)
+
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), b.config.BatchTimeout)
+		if err := b.exporter.ExportSpans(ctx, batch); err != nil {
+			logging.Default().Warn("failed to export spans",
+				slog.Int("count", len(batch)),
+				logging.Err(err),
+			)
+		}
+		cancel()
+		batch = make([]SpanData, 0, b.config.MaxBatchSize)
+	}
+
+	for {
+		select {
+		case span := <-b.queue:
+			batch = append(batch, span)
+			if len(batch) >= b.config.MaxBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-b.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-b.queue:
+					batch = append(batch, span)
+					if len(batch) >= b.config.MaxBatchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		case <-b.done:
+			return
+		}
+	}
+}
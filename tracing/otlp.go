@@ -0,0 +1,259 @@
+// tracing/otlp.go
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otlpTracesPath is the OTLP/HTTP traces endpoint path
+const otlpTracesPath = "/v1/traces"
+
+// instrumentationScope names this package in exported spans
+const instrumentationScope = "github.com/JorgeSaicoski/microservice-commons/tracing"
+
+// OTLPConfig holds configuration for the OTLP/HTTP exporter
+type OTLPConfig struct {
+	Endpoint       string // Collector base URL; /v1/traces is appended unless already present
+	Headers        map[string]string
+	Timeout        time.Duration
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Client         *http.Client // Optional custom HTTP client
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding
+type OTLPExporter struct {
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	resource otlpResource
+}
+
+// NewOTLPExporter creates an OTLP/HTTP exporter
+func NewOTLPExporter(config OTLPConfig) *OTLPExporter {
+	url := strings.TrimRight(config.Endpoint, "/")
+	if !strings.HasSuffix(url, otlpTracesPath) {
+		url += otlpTracesPath
+	}
+
+	client := config.Client
+	if client == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	resource := otlpResource{Attributes: otlpAttributes(map[string]interface{}{
+		"service.name": config.ServiceName,
+	})}
+	if config.ServiceVersion != "" {
+		resource.Attributes = append(resource.Attributes, otlpAttribute("service.version", config.ServiceVersion))
+	}
+	if config.Environment != "" {
+		resource.Attributes = append(resource.Attributes, otlpAttribute("deployment.environment", config.Environment))
+	}
+
+	return &OTLPExporter{
+		url:      url,
+		headers:  config.Headers,
+		client:   client,
+		resource: resource,
+	}
+}
+
+// ExportSpans sends the spans to the collector
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload := otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: e.resource,
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationScope},
+				Spans: make([]otlpSpan, 0, len(spans)),
+			}},
+		}},
+	}
+	for _, span := range spans {
+		payload.ResourceSpans[0].ScopeSpans[0].Spans = append(payload.ResourceSpans[0].ScopeSpans[0].Spans, toOTLPSpan(span))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Shutdown is a no-op; the exporter holds no background resources
+func (e *OTLPExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// OTLP/JSON wire types. IDs are hex encoded and timestamps are decimal
+// strings of Unix nanoseconds, as the OTLP JSON mapping requires.
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	TraceState        string         `json:"traceState,omitempty"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// toOTLPSpan converts a finished span to its wire representation
+func toOTLPSpan(span SpanData) otlpSpan {
+	result := otlpSpan{
+		TraceID:           span.SpanContext.TraceID.String(),
+		SpanID:            span.SpanContext.SpanID.String(),
+		TraceState:        span.SpanContext.TraceState,
+		Name:              span.Name,
+		Kind:              int(span.Kind),
+		StartTimeUnixNano: unixNano(span.StartTime),
+		EndTimeUnixNano:   unixNano(span.EndTime),
+		Attributes:        otlpAttributes(span.Attributes),
+		Status:            otlpStatus{Code: int(span.Status), Message: span.StatusMessage},
+	}
+
+	if span.ParentSpanID.IsValid() {
+		result.ParentSpanID = span.ParentSpanID.String()
+	}
+
+	for _, event := range span.Events {
+		result.Events = append(result.Events, otlpEvent{
+			TimeUnixNano: unixNano(event.Time),
+			Name:         event.Name,
+			Attributes:   otlpAttributes(event.Attributes),
+		})
+	}
+
+	return result
+}
+
+// otlpAttributes converts an attribute map to OTLP key/values
+func otlpAttributes(attributes map[string]interface{}) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	result := make([]otlpKeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		result = append(result, otlpAttribute(key, value))
+	}
+	return result
+}
+
+// otlpAttribute converts a single attribute, stringifying unsupported types
+func otlpAttribute(key string, value interface{}) otlpKeyValue {
+	var v otlpValue
+
+	switch typed := value.(type) {
+	case string:
+		v.StringValue = &typed
+	case bool:
+		v.BoolValue = &typed
+	case int:
+		s := strconv.FormatInt(int64(typed), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(typed, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &typed
+	default:
+		s := fmt.Sprint(typed)
+		v.StringValue = &s
+	}
+
+	return otlpKeyValue{Key: key, Value: v}
+}
+
+// unixNano formats a time as a decimal Unix nanosecond string
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
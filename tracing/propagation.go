@@ -0,0 +1,110 @@
+// tracing/propagation.go
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context headers
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// traceparentVersion is the only traceparent version this package emits
+const traceparentVersion = "00"
+
+// maxTracestateLength bounds the propagated tracestate, as recommended by
+// the W3C specification
+const maxTracestateLength = 512
+
+// ParseTraceparent parses a W3C traceparent header value
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent: %q", value)
+	}
+
+	version := parts[0]
+	if len(version) != 2 || version == "ff" {
+		return SpanContext{}, fmt.Errorf("unsupported traceparent version: %q", version)
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if version == traceparentVersion && len(parts) != 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent: %q", value)
+	}
+
+	var sc SpanContext
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace ID: %w", err)
+	}
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span ID: %w", err)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace flags: %w", err)
+	}
+	sc.Flags = flags[0]
+
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("traceparent has zero trace or span ID")
+	}
+
+	sc.Remote = true
+	return sc, nil
+}
+
+// FormatTraceparent renders a span context as a W3C traceparent value
+func FormatTraceparent(sc SpanContext) string {
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceID, sc.SpanID, sc.Flags&FlagsSampled)
+}
+
+// Extract reads a span context from traceparent and tracestate headers
+func Extract(header http.Header) (SpanContext, bool) {
+	value := header.Get(TraceparentHeader)
+	if value == "" {
+		return SpanContext{}, false
+	}
+
+	sc, err := ParseTraceparent(value)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	if state := strings.Join(header.Values(TracestateHeader), ","); len(state) <= maxTracestateLength {
+		sc.TraceState = state
+	}
+
+	return sc, true
+}
+
+// Inject writes traceparent and tracestate headers for a span context
+func Inject(sc SpanContext, header http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(TraceparentHeader, FormatTraceparent(sc))
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+// decodeHex decodes a lowercase hex string of exactly len(dst) bytes
+func decodeHex(value string, dst []byte) error {
+	if len(value) != hex.EncodedLen(len(dst)) {
+		return fmt.Errorf("expected %d hex characters, got %d", hex.EncodedLen(len(dst)), len(value))
+	}
+	if strings.ToLower(value) != value {
+		return fmt.Errorf("hex must be lowercase")
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
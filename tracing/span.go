@@ -0,0 +1,261 @@
+// tracing/span.go
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the lowercase hex encoding of the trace ID
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid returns true if the trace ID is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the lowercase hex encoding of the span ID
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid returns true if the span ID is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// FlagsSampled is the W3C trace flag marking a trace as sampled
+const FlagsSampled byte = 0x01
+
+// SpanContext holds the identifiers propagated between services
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	Remote     bool
+}
+
+// IsValid returns true if both trace and span IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns true if the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagsSampled != 0
+}
+
+// SpanKind describes the role of a span
+type SpanKind int
+
+// Span kinds, numbered as in OTLP
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+	SpanKindProducer SpanKind = 4
+	SpanKindConsumer SpanKind = 5
+)
+
+// StatusCode is the outcome of a span
+type StatusCode int
+
+// Status codes, numbered as in OTLP
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Event is a timestamped annotation on a span
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// SpanData is an immutable snapshot of a finished span, passed to exporters
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	ParentSpanID  SpanID
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]interface{}
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+	ServiceName   string
+}
+
+// Duration returns how long the span took
+func (sd SpanData) Duration() time.Duration {
+	return sd.EndTime.Sub(sd.StartTime)
+}
+
+// Span is a single timed operation within a trace. Spans of unsampled
+// traces still carry IDs for propagation but record nothing.
+type Span struct {
+	mu            sync.Mutex
+	tracer        *Tracer
+	name          string
+	kind          SpanKind
+	spanContext   SpanContext
+	parentSpanID  SpanID
+	startTime     time.Time
+	endTime       time.Time
+	attributes    map[string]interface{}
+	events        []Event
+	status        StatusCode
+	statusMessage string
+	ended         bool
+}
+
+// SpanContext returns the span's propagation identifiers
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.spanContext
+}
+
+// IsRecording returns true if the span records data for export
+func (s *Span) IsRecording() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spanContext.IsSampled() && !s.ended
+}
+
+// SetName updates the span name
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttribute records a key/value pair on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// AddEvent records a timestamped event on the span
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, Event{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// SetStatus sets the span status. An error status is never downgraded.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status == StatusError && code != StatusError {
+		return
+	}
+	s.status = code
+	s.statusMessage = message
+}
+
+// RecordError records err as an exception event and marks the span as failed
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", map[string]interface{}{
+		"exception.message": err.Error(),
+	})
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End finishes the span and hands it to the tracer's exporter. Calling
+// End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.endTime = time.Now()
+	sampled := s.spanContext.IsSampled()
+	s.mu.Unlock()
+
+	if sampled && s.tracer != nil {
+		s.tracer.export(s.snapshot())
+	}
+}
+
+// snapshot copies the span into SpanData
+func (s *Span) snapshot() SpanData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attributes := make(map[string]interface{}, len(s.attributes))
+	for key, value := range s.attributes {
+		attributes[key] = value
+	}
+
+	return SpanData{
+		Name:          s.name,
+		Kind:          s.kind,
+		SpanContext:   s.spanContext,
+		ParentSpanID:  s.parentSpanID,
+		StartTime:     s.startTime,
+		EndTime:       s.endTime,
+		Attributes:    attributes,
+		Events:        append([]Event(nil), s.events...),
+		Status:        s.status,
+		StatusMessage: s.statusMessage,
+		ServiceName:   s.tracer.serviceName,
+	}
+}
+
+// newTraceID returns a random trace ID
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// newSpanID returns a random span ID
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,213 @@
+// tracing/tracer.go
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/JorgeSaicoski/microservice-commons/config"
+	"github.com/JorgeSaicoski/microservice-commons/logging"
+)
+
+// Options holds tracer configuration
+type Options struct {
+	ServiceName string
+	Exporter    Exporter // nil = spans are created and propagated but not exported
+	SampleRatio float64  // Fraction of new traces that are sampled (0-1)
+}
+
+// Tracer creates spans and hands finished ones to an exporter
+type Tracer struct {
+	serviceName string
+	exporter    Exporter
+	sampleRatio float64
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+func init() {
+	defaultTracer.Store(NewTracer(Options{SampleRatio: 1}))
+}
+
+// NewTracer creates a tracer with the given options
+func NewTracer(opts Options) *Tracer {
+	return &Tracer{
+		serviceName: opts.ServiceName,
+		exporter:    opts.Exporter,
+		sampleRatio: opts.SampleRatio,
+	}
+}
+
+// NewTracerFromConfig creates a tracer exporting over OTLP/HTTP in
+// batches when an endpoint is configured
+func NewTracerFromConfig(cfg *config.Config) *Tracer {
+	tc := cfg.TracingConfig
+
+	opts := Options{
+		ServiceName: cfg.ServiceName,
+		SampleRatio: tc.SampleRatio,
+	}
+
+	if tc.IsExportEnabled() {
+		opts.Exporter = NewBatchExporter(NewOTLPExporter(OTLPConfig{
+			Endpoint:       tc.OTLPEndpoint,
+			Headers:        tc.OTLPHeaders,
+			Timeout:        tc.ExportTimeout,
+			ServiceName:    cfg.ServiceName,
+			ServiceVersion: cfg.ServiceVersion,
+			Environment:    cfg.Environment,
+		}), BatchConfig{
+			MaxBatchSize: tc.BatchSize,
+			BatchTimeout: tc.BatchTimeout,
+		})
+	}
+
+	return NewTracer(opts)
+}
+
+// Default returns the process-wide tracer
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// SetDefault replaces the process-wide tracer
+func SetDefault(tracer *Tracer) {
+	defaultTracer.Store(tracer)
+}
+
+// Start creates a span using the default tracer
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return Default().Start(ctx, name, kind)
+}
+
+// Start creates a span as a child of the span or remote span context in
+// ctx, or a new root span, and returns a context carrying it
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	spanContext := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		spanContext.TraceID = parent.TraceID
+		spanContext.Flags = parent.Flags
+		spanContext.TraceState = parent.TraceState
+	} else {
+		spanContext.TraceID = newTraceID()
+		if t.shouldSample(spanContext.TraceID) {
+			spanContext.Flags = FlagsSampled
+		}
+	}
+
+	span := &Span{
+		tracer:      t,
+		name:        name,
+		kind:        kind,
+		spanContext: spanContext,
+		startTime:   time.Now(),
+	}
+	if parent.IsValid() {
+		span.parentSpanID = parent.SpanID
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown flushes and stops the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+// shouldSample decides whether a new trace is sampled. The decision is
+// derived from the trace ID so it is consistent across services.
+func (t *Tracer) shouldSample(traceID TraceID) bool {
+	switch {
+	case t.sampleRatio >= 1:
+		return true
+	case t.sampleRatio <= 0:
+		return false
+	default:
+		bound := uint64(t.sampleRatio * (1 << 63))
+		return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+	}
+}
+
+// export passes a finished span to the exporter
+func (t *Tracer) export(span SpanData) {
+	if t.exporter == nil {
+		return
+	}
+	if err := t.exporter.ExportSpans(context.Background(), []SpanData{span}); err != nil {
+		logging.Default().Warn("failed to export span",
+			slog.String("span", span.Name),
+			logging.Err(err),
+		)
+	}
+}
+
+type spanContextKey struct{}
+
+type remoteSpanContextKey struct{}
+
+// ContextWithSpan returns a context carrying span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a context carrying a span context
+// received from another service, used as the parent of the next span
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span, or
+// the remote span context if no local span has started
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	if ctx == nil {
+		return SpanContext{}
+	}
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return sc
+}
+
+// TraceIDFromContext returns the hex trace ID in ctx, or an empty string
+func TraceIDFromContext(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.TraceID.IsValid() {
+		return ""
+	}
+	return sc.TraceID.String()
+}
+
+// InjectContext writes the span context in ctx to outgoing request headers
+func InjectContext(ctx context.Context, header http.Header) {
+	Inject(SpanContextFromContext(ctx), header)
+}
+
+// ExtractContext reads a span context from incoming headers and returns a
+// context carrying it as the remote parent
+func ExtractContext(ctx context.Context, header http.Header) context.Context {
+	sc, ok := Extract(header)
+	if !ok {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
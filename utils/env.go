@@ -4,6 +4,7 @@ package utils
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // GetEnv gets environment variable with fallback
@@ -33,3 +34,23 @@ func GetEnvBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+// GetEnvDuration gets environment variable as time.Duration with fallback
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
+// GetEnvFloat gets environment variable as float64 with fallback
+func GetEnvFloat(key string, fallback float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return fallback
+}